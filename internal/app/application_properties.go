@@ -0,0 +1,271 @@
+package app
+
+import (
+	"encoding/base64"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Application properties are written as "key=value; other=42". A bare value is typed by its looks: an integer is
+// sent as an int64, a decimal number as a float64, true or false as a bool and anything else as a string. A quoted
+// value ("00123", "a;b") is always a string, and other types are spelled out, e.g. count:int32=5 or
+// at:time=2024-05-01T10:00:00Z. formatProperties writes properties so parseProperties reads them back with the
+// same values and types.
+
+// propertyTypes are the types that can be spelled out after the key.
+var propertyTypes = []string{
+	"string", "bool", "int8", "int16", "int32", "int64", "uint8", "uint16", "uint32", "uint64",
+	"float32", "float64", "time", "binary",
+}
+
+// bareKey matches the keys written without quotes.
+var bareKey = regexp.MustCompile(`^[^\s;=:"]+$`)
+
+// formatProperties is the inverse of parseProperties. Values of types it can't spell out, such as UUIDs, are
+// written as strings.
+func formatProperties(props map[string]any) string {
+	keys := make([]string, 0, len(props))
+	for k := range props {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+
+	pairs := make([]string, 0, len(keys))
+	for _, k := range keys {
+		key := k
+		if !bareKey.MatchString(k) {
+			key = strconv.Quote(k)
+		}
+		typ, value := formatPropertyValue(props[k])
+		if typ != "" {
+			key += ":" + typ
+		}
+		pairs = append(pairs, key+"="+value)
+	}
+	return strings.Join(pairs, "; ")
+}
+
+// formatPropertyValue returns the value as written after the "=", and its type when it has to be spelled out.
+func formatPropertyValue(v any) (typ, value string) {
+	switch v := v.(type) {
+	case string:
+		return "", strconv.Quote(v)
+	case bool:
+		return "", strconv.FormatBool(v)
+	case int64:
+		return "", strconv.FormatInt(v, 10)
+	case float64:
+		s := strconv.FormatFloat(v, 'g', -1, 64)
+		if _, err := strconv.ParseInt(s, 10, 64); err == nil {
+			return "float64", s
+		}
+		return "", s
+	case int8:
+		return "int8", strconv.FormatInt(int64(v), 10)
+	case int16:
+		return "int16", strconv.FormatInt(int64(v), 10)
+	case int32:
+		return "int32", strconv.FormatInt(int64(v), 10)
+	case int:
+		return "", strconv.Itoa(v)
+	case uint8:
+		return "uint8", strconv.FormatUint(uint64(v), 10)
+	case uint16:
+		return "uint16", strconv.FormatUint(uint64(v), 10)
+	case uint32:
+		return "uint32", strconv.FormatUint(uint64(v), 10)
+	case uint64:
+		return "uint64", strconv.FormatUint(v, 10)
+	case float32:
+		return "float32", strconv.FormatFloat(float64(v), 'g', -1, 32)
+	case time.Time:
+		return "time", v.Format(time.RFC3339Nano)
+	case []byte:
+		return "binary", base64.StdEncoding.EncodeToString(v)
+	default:
+		return "", strconv.Quote(fmt.Sprint(v))
+	}
+}
+
+// parseProperties parses "key=value; other=42" into application properties, see formatProperties.
+func parseProperties(s string) (map[string]any, error) {
+	pairs, err := splitProperties(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(pairs) == 0 {
+		return nil, nil
+	}
+
+	props := make(map[string]any, len(pairs))
+	for _, pair := range pairs {
+		key, value, err := parseProperty(pair)
+		if err != nil {
+			return nil, err
+		}
+		props[key] = value
+	}
+	return props, nil
+}
+
+// splitProperties splits s at the semicolons that are not quoted.
+func splitProperties(s string) ([]string, error) {
+	var pairs []string
+	for s = strings.TrimSpace(s); s != ""; s = strings.TrimSpace(s) {
+		end := 0
+		for end < len(s) && s[end] != ';' {
+			if s[end] != '"' {
+				end++
+				continue
+			}
+			quoted, err := strconv.QuotedPrefix(s[end:])
+			if err != nil {
+				return nil, fmt.Errorf("unterminated quote in %q", s)
+			}
+			end += len(quoted)
+		}
+
+		if pair := strings.TrimSpace(s[:end]); pair != "" {
+			pairs = append(pairs, pair)
+		}
+		if end == len(s) {
+			break
+		}
+		s = s[end+1:]
+	}
+	return pairs, nil
+}
+
+func parseProperty(pair string) (string, any, error) {
+	invalid := fmt.Errorf("invalid property %q, expected key=value", pair)
+
+	var key, rest string
+	if strings.HasPrefix(pair, `"`) {
+		quoted, err := strconv.QuotedPrefix(pair)
+		if err != nil {
+			return "", nil, invalid
+		}
+		key, _ = strconv.Unquote(quoted)
+		rest = strings.TrimSpace(pair[len(quoted):])
+	} else {
+		end := strings.IndexAny(pair, ":=")
+		if end < 0 {
+			return "", nil, invalid
+		}
+		key = strings.TrimSpace(pair[:end])
+		rest = pair[end:]
+	}
+
+	typ := ""
+	if strings.HasPrefix(rest, ":") {
+		var found bool
+		typ, rest, found = strings.Cut(rest[1:], "=")
+		if !found {
+			return "", nil, invalid
+		}
+		typ = strings.TrimSpace(typ)
+		rest = "=" + rest
+	}
+	value, found := strings.CutPrefix(rest, "=")
+	if !found || key == "" {
+		return "", nil, invalid
+	}
+
+	v, err := parsePropertyValue(typ, strings.TrimSpace(value))
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid property %s: %w", key, err)
+	}
+	return key, v, nil
+}
+
+// parsePropertyValue converts s to typ, or to the type s looks like when typ is empty.
+func parsePropertyValue(typ, s string) (any, error) {
+	quoted := strings.HasPrefix(s, `"`)
+	if quoted {
+		unquoted, err := strconv.Unquote(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid quoted value %s", s)
+		}
+		s = unquoted
+	}
+
+	switch typ {
+	case "":
+		if quoted {
+			return s, nil
+		}
+		if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+			return i, nil
+		}
+		if f, err := strconv.ParseFloat(s, 64); err == nil {
+			return f, nil
+		}
+		if s == "true" || s == "false" {
+			return s == "true", nil
+		}
+		return s, nil
+	case "string":
+		return s, nil
+	case "bool":
+		return strconv.ParseBool(s)
+	case "int8", "int16", "int32", "int64":
+		bits, _ := strconv.Atoi(strings.TrimPrefix(typ, "int"))
+		i, err := strconv.ParseInt(s, 10, bits)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not an %s", s, typ)
+		}
+		switch bits {
+		case 8:
+			return int8(i), nil
+		case 16:
+			return int16(i), nil
+		case 32:
+			return int32(i), nil
+		}
+		return i, nil
+	case "uint8", "uint16", "uint32", "uint64":
+		bits, _ := strconv.Atoi(strings.TrimPrefix(typ, "uint"))
+		u, err := strconv.ParseUint(s, 10, bits)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a %s", s, typ)
+		}
+		switch bits {
+		case 8:
+			return uint8(u), nil
+		case 16:
+			return uint16(u), nil
+		case 32:
+			return uint32(u), nil
+		}
+		return u, nil
+	case "float32":
+		f, err := strconv.ParseFloat(s, 32)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a float32", s)
+		}
+		return float32(f), nil
+	case "float64":
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a float64", s)
+		}
+		return f, nil
+	case "time":
+		t, err := time.Parse(time.RFC3339Nano, s)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not an RFC 3339 time", s)
+		}
+		return t, nil
+	case "binary":
+		b, err := base64.StdEncoding.DecodeString(s)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not base64", s)
+		}
+		return b, nil
+	}
+	return nil, fmt.Errorf("unknown type %q, expected one of %s", typ, strings.Join(propertyTypes, ", "))
+}
@@ -0,0 +1,91 @@
+package app
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestPropertiesRoundTrip(t *testing.T) {
+	tests := []map[string]any{
+		nil,
+		{"color": "red"},
+		{"quantity": int64(12), "price": 9.5, "priority": true, "disabled": false},
+		{"whole": 3.0, "negative": -2.5e-10, "big": int64(-1 << 63)},
+		{"zip": "00123", "answer": "42", "yes": "true", "decimal": "1.5", "empty": ""},
+		{"list": "a;b", "pair": "k=v", "typed": "count:int32=5", "quote": `say "hi"`, "backslash": `C:\temp`},
+		{"multiline": "first\nsecond\r\nthird", "tab": "a\tb", "padded": "  spaced  "},
+		{"with space": "yes", "a;b": "c", "k=v": int64(1), "x:y": "z", `"quoted"`: "q", "line\nbreak": "n"},
+		{"i8": int8(-8), "i16": int16(16), "i32": int32(-32)},
+		{"u8": uint8(8), "u16": uint16(16), "u32": uint32(32), "u64": uint64(1 << 63)},
+		{"f32": float32(1.25), "f32whole": float32(2)},
+		{"at": time.Date(2024, 5, 1, 10, 0, 0, 123456789, time.UTC)},
+		{"blob": []byte{0, 1, 2, 0xff}, "noblob": []byte{}},
+	}
+
+	for _, props := range tests {
+		text := formatProperties(props)
+		got, err := parseProperties(text)
+		if err != nil {
+			t.Errorf("parseProperties(%q): %v", text, err)
+			continue
+		}
+		if len(props) == 0 && len(got) == 0 {
+			continue
+		}
+		if !reflect.DeepEqual(got, props) {
+			t.Errorf("round trip of %#v through %q gave %#v", props, text, got)
+		}
+	}
+}
+
+func TestParseProperties(t *testing.T) {
+	tests := []struct {
+		text string
+		want map[string]any
+	}{
+		{"", nil},
+		{" ; ", nil},
+		{"color=red", map[string]any{"color": "red"}},
+		{"a=1; b=2.5; c=true; d=True", map[string]any{"a": int64(1), "b": 2.5, "c": true, "d": "True"}},
+		{`zip="00123"; note = "a;b=c" ;`, map[string]any{"zip": "00123", "note": "a;b=c"}},
+		{`count:int32=5; at:time=2024-05-01T10:00:00Z`, map[string]any{"count": int32(5), "at": time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)}},
+		{`n:string=42; f:float64=3`, map[string]any{"n": "42", "f": 3.0}},
+		{`"my key"="x"; "a\nb":uint8=7`, map[string]any{"my key": "x", "a\nb": uint8(7)}},
+		{`text="line\nbreak"`, map[string]any{"text": "line\nbreak"}},
+		{`url=https://example.com/?a=b`, map[string]any{"url": "https://example.com/?a=b"}},
+	}
+
+	for _, tt := range tests {
+		got, err := parseProperties(tt.text)
+		if err != nil {
+			t.Errorf("parseProperties(%q): %v", tt.text, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseProperties(%q) = %#v, want %#v", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestParsePropertiesErrors(t *testing.T) {
+	tests := []string{
+		"color",
+		"=red",
+		`note="unterminated`,
+		`"key=x`,
+		"count:int8=300",
+		"count:uint8=-1",
+		"flag:bool=maybe",
+		"at:time=yesterday",
+		"blob:binary=not base64!",
+		"x:decimal=1",
+		"x:int32",
+	}
+
+	for _, text := range tests {
+		if got, err := parseProperties(text); err == nil {
+			t.Errorf("parseProperties(%q) = %#v, expected an error", text, got)
+		}
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/MonsieurTib/service-bus-tui/internal/azure"
+	"github.com/MonsieurTib/service-bus-tui/internal/config"
 	"github.com/MonsieurTib/service-bus-tui/internal/styles"
 	"github.com/charmbracelet/bubbles/spinner"
 	"github.com/charmbracelet/bubbles/textinput"
@@ -24,31 +25,73 @@ const (
 	AzureCLI CredentialType = iota
 	InteractiveBrowser
 	ConnectionString
+	ServicePrincipalSecret
+	ServicePrincipalCertificate
+	DeviceCode
+	ManagedIdentitySystem
+	ManagedIdentityUser
+	WorkloadIdentity
+	EnvironmentOrManagedIdentity
 )
 
 type AuthModel struct {
+	profiles               []config.Profile
+	inProfileMode          bool
+	selectedProfileIdx     int // len(profiles) selects "New connection"
+	confirmDeleteProfile   bool
+	profile                *config.Profile // profile being connected, which overrides the selected credential type
+	cloud                  azure.Cloud     // cloud selected with --cloud, restored when connecting a profile is cancelled
+	profileCredentialType  CredentialType
+	startProfile           *config.Profile // profile given with --profile, connected from Init
+	inProfileNameMode      bool
+	profileNameInput       textinput.Model
 	selectedAuth           CredentialType
 	authOptions            []string
 	credentialTypes        []CredentialType
 	inNamespaceMode        bool
 	inConnectionStringMode bool
 	connectionStringInput  textinput.Model
-	errMsg                 string
-	isAuthenticating       bool
-	namespaces             []azure.NamespaceInfo
-	selectedNamespaceIdx   int
-	authenticatedUser      string
-	spinner                spinner.Model
-	height                 int
-	scrollOffset           int
+	// Saved connection strings; the connection string input stays open while its name and passphrase are asked.
+	savedConnectionStrings     []config.SavedConnectionString
+	inSavedConnectionMode      bool
+	selectedSavedIdx           int // len(savedConnectionStrings) selects "Enter a connection string"
+	confirmDeleteSaved         bool
+	inConnectionStringNameMode bool
+	connectionStringNameInput  textinput.Model
+	checkingKeyring            bool // the name is entered, waiting for KeyringCheckedMsg
+	inStoreMode                bool
+	selectedStoreIdx           int // index in connectionStringStores
+	inPassphraseMode           bool
+	passphraseInput            textinput.Model
+	passphraseConfirmInput     textinput.Model
+	confirmingPassphrase       bool
+	pendingConnectionString    *config.SavedConnectionString // being saved or loaded once the passphrase is entered
+	inServicePrincipalMode     bool
+	spInputs                   [spFieldCount]textinput.Model
+	spFocus                    int // index in servicePrincipalFields
+	deviceCode                 *azure.DeviceCodeCredential
+	deviceCodePrompt           *azure.DeviceCodePrompt
+	cancelDeviceCode           context.CancelFunc // cancels the device code login while it waits for the sign-in
+	inClientIDMode             bool
+	clientIDInput              textinput.Model
+	errMsg                     string
+	isAuthenticating           bool
+	namespaces                 []azure.NamespaceInfo
+	selectedNamespaceIdx       int
+	authenticatedUser          string
+	spinner                    spinner.Model
+	height                     int
+	scrollOffset               int
 }
 
-func NewAuthModel() *AuthModel {
+// NewAuthModel starts with the profile picker when profiles are saved, or connects straight to startProfile when it
+// is not nil.
+func NewAuthModel(startProfile *config.Profile) *AuthModel {
 	s := spinner.New()
 	s.Spinner = spinner.Dot
 
 	ti := textinput.New()
-	ti.Placeholder = "Endpoint=sb://...;SharedAccessKeyName=...;SharedAccessKey=..."
+	ti.Placeholder = "Endpoint=sb://...;SharedAccessKeyName=...;SharedAccessKey=... (add UseDevelopmentEmulator=true for the emulator)"
 	ti.EchoMode = textinput.EchoPassword
 	ti.EchoCharacter = '*'
 	ti.Width = 80
@@ -56,17 +99,37 @@ func NewAuthModel() *AuthModel {
 	m := &AuthModel{
 		authOptions: []string{
 			"Interactive Browser",
+			"Device Code (sign in from another device)",
 			"Connection String",
+			"Service Principal (client secret)",
+			"Service Principal (certificate)",
+			"Managed Identity (system-assigned)",
+			"Managed Identity (user-assigned)",
+			"Workload Identity",
 		},
 		credentialTypes: []CredentialType{
 			InteractiveBrowser,
+			DeviceCode,
 			ConnectionString,
+			ServicePrincipalSecret,
+			ServicePrincipalCertificate,
+			ManagedIdentitySystem,
+			ManagedIdentityUser,
+			WorkloadIdentity,
 		},
-		selectedAuth:          0,
-		connectionStringInput: ti,
-		spinner:               s,
-		height:                50,
-		scrollOffset:          0,
+		selectedAuth:              0,
+		connectionStringInput:     ti,
+		spInputs:                  newServicePrincipalInputs(),
+		clientIDInput:             newClientIDInput(),
+		profileNameInput:          newProfileNameInput(),
+		connectionStringNameInput: newConnectionStringNameInput(),
+		passphraseInput:           newPassphraseInput(),
+		passphraseConfirmInput:    newPassphraseInput(),
+		startProfile:              startProfile,
+		cloud:                     azure.CurrentCloud(),
+		spinner:                   s,
+		height:                    50,
+		scrollOffset:              0,
 	}
 
 	if user, ok := azure.GetAzureCliAuthenticatedUser(); ok {
@@ -82,14 +145,28 @@ func NewAuthModel() *AuthModel {
 		m.selectedAuth = 0
 	}
 
+	profiles, err := config.LoadProfiles()
+	if err != nil {
+		m.errMsg = err.Error()
+	}
+	m.profiles = profiles
+	m.inProfileMode = len(profiles) > 0
+
 	return m
 }
 
 func (m *AuthModel) Init() tea.Cmd {
-	return tea.Batch(m.spinner.Tick, textinput.Blink)
+	cmds := []tea.Cmd{m.spinner.Tick, textinput.Blink, detectManagedIdentityCmd()}
+	if m.startProfile != nil {
+		cmds = append(cmds, m.connectProfile(*m.startProfile))
+	}
+	return tea.Batch(cmds...)
 }
 
 func (m *AuthModel) selectedCredentialType() CredentialType {
+	if m.profile != nil {
+		return m.profileCredentialType
+	}
 	if int(m.selectedAuth) < len(m.credentialTypes) {
 		return m.credentialTypes[m.selectedAuth]
 	}
@@ -106,23 +183,107 @@ func (m *AuthModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case "ctrl+c":
 			return m, tea.Quit
 		case "esc":
+			if m.cancelDeviceCode != nil {
+				m.stopDeviceCodeLogin()
+				m.isAuthenticating = false
+				m.errMsg = ""
+				m.cancelProfile()
+				return m, spinnerCmd
+			}
+			if m.inProfileNameMode {
+				m.inProfileNameMode = false
+				m.errMsg = ""
+				return m, spinnerCmd
+			}
+			if m.inProfileMode {
+				if m.confirmDeleteProfile {
+					m.confirmDeleteProfile = false
+				} else {
+					m.inProfileMode = false
+				}
+				m.errMsg = ""
+				return m, spinnerCmd
+			}
+			if m.inStoreMode {
+				m.inStoreMode = false
+				m.inConnectionStringNameMode = true
+				m.pendingConnectionString = nil
+				m.errMsg = ""
+				return m, spinnerCmd
+			}
+			if m.inPassphraseMode {
+				m.inPassphraseMode = false
+				m.confirmingPassphrase = false
+				m.pendingConnectionString = nil
+				m.inSavedConnectionMode = !m.inConnectionStringMode
+				m.errMsg = ""
+				return m, spinnerCmd
+			}
+			if m.inConnectionStringNameMode {
+				m.inConnectionStringNameMode = false
+				m.checkingKeyring = false
+				m.pendingConnectionString = nil
+				m.errMsg = ""
+				return m, spinnerCmd
+			}
+			if m.inSavedConnectionMode {
+				if m.confirmDeleteSaved {
+					m.confirmDeleteSaved = false
+				} else {
+					m.inSavedConnectionMode = false
+				}
+				m.errMsg = ""
+				return m, spinnerCmd
+			}
 			if m.inConnectionStringMode {
 				m.inConnectionStringMode = false
 				m.connectionStringInput.SetValue("")
 				m.errMsg = ""
 				return m, spinnerCmd
 			}
+			if m.inServicePrincipalMode {
+				m.inServicePrincipalMode = false
+				m.errMsg = ""
+				m.cancelProfile()
+				return m, spinnerCmd
+			}
+			if m.inClientIDMode {
+				m.inClientIDMode = false
+				m.errMsg = ""
+				return m, spinnerCmd
+			}
 			if m.inNamespaceMode {
 				m.inNamespaceMode = false
 				m.namespaces = nil
 				m.errMsg = ""
 				m.scrollOffset = 0
+				return m, spinnerCmd
+			}
+			if len(m.profiles) > 0 && !m.isAuthenticating {
+				m.inProfileMode = true
+				m.errMsg = ""
 			}
 			return m, spinnerCmd
 		}
 
-		if m.inConnectionStringMode {
+		if m.inProfileMode {
+			return m.updateProfileSelection(msg)
+		} else if m.inProfileNameMode {
+			return m.updateProfileNameInput(msg)
+		} else if m.inSavedConnectionMode {
+			return m.updateSavedConnectionSelection(msg)
+		} else if m.inStoreMode {
+			return m.updateStoreSelection(msg)
+		} else if m.inPassphraseMode {
+			return m.updatePassphraseInput(msg)
+		} else if m.inConnectionStringNameMode {
+			return m.updateConnectionStringNameInput(msg)
+		} else if m.inConnectionStringMode {
 			return m.updateConnectionStringInput(msg)
+		} else if m.inServicePrincipalMode {
+			return m.updateServicePrincipalInput(msg)
+		} else if m.inClientIDMode {
+			return m.updateClientIDInput(msg)
 		} else if m.inNamespaceMode {
 			return m.updateNamespaceSelection(msg)
 		} else {
@@ -132,17 +293,34 @@ func (m *AuthModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case tea.WindowSizeMsg:
 		m.height = max(msg.Height-5, 5)
 
+	case ManagedIdentityDetectedMsg:
+		m.addDetectedIdentity(msg.Description)
+		return m, spinnerCmd
+
+	case KeyringCheckedMsg:
+		return m, tea.Batch(spinnerCmd, m.handleKeyringChecked(msg))
+
+	case DeviceCodePromptMsg:
+		m.deviceCodePrompt = &msg.Prompt
+		return m, spinnerCmd
+
 	case NamespacesLoadedMsg:
+		m.stopDeviceCodeLogin()
 		m.inNamespaceMode = true
 		m.namespaces = msg.Namespaces
 		m.selectedNamespaceIdx = 0
 		m.scrollOffset = 0
 		m.isAuthenticating = false
+		if m.profile != nil {
+			return m, tea.Batch(spinnerCmd, m.connectListedProfileNamespace(msg.Namespaces))
+		}
 		return m, spinnerCmd
 
 	case ErrorMsg:
 		m.errMsg = string(msg)
 		m.isAuthenticating = false
+		m.stopDeviceCodeLogin()
+		m.cancelProfile()
 		return m, spinnerCmd
 	}
 
@@ -159,6 +337,8 @@ func (m *AuthModel) updateNamespaceSelection(msg tea.KeyMsg) (tea.Model, tea.Cmd
 		if m.selectedNamespaceIdx < len(m.namespaces)-1 {
 			m.selectedNamespaceIdx++
 		}
+	case "ctrl+s":
+		return m, m.openProfileNameInput()
 	case "enter":
 		m.isAuthenticating = true
 		return m, m.connectWithNamespaceCmd(m.namespaces[m.selectedNamespaceIdx].Name)
@@ -178,10 +358,17 @@ func (m *AuthModel) updateAuthSelection(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 	case "enter":
 		m.errMsg = ""
-		if m.selectedCredentialType() == ConnectionString {
-			m.inConnectionStringMode = true
-			m.connectionStringInput.Focus()
-			return m, textinput.Blink
+		switch m.selectedCredentialType() {
+		case ConnectionString:
+			return m, m.openConnectionStrings()
+		case ServicePrincipalSecret, ServicePrincipalCertificate:
+			return m, m.openServicePrincipalInput()
+		case DeviceCode:
+			return m, m.startDeviceCodeLogin()
+		case ManagedIdentityUser:
+			m.inClientIDMode = true
+			m.clientIDInput.CursorEnd()
+			return m, m.clientIDInput.Focus()
 		}
 		m.isAuthenticating = true
 		return m, tea.Batch(m.spinner.Tick, m.authenticateAndListNamespacesCmd())
@@ -191,6 +378,8 @@ func (m *AuthModel) updateAuthSelection(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 
 func (m *AuthModel) updateConnectionStringInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch msg.String() {
+	case "ctrl+s":
+		return m, m.openConnectionStringNameInput()
 	case "enter":
 		connStr := strings.TrimSpace(m.connectionStringInput.Value())
 		if connStr == "" {
@@ -216,9 +405,28 @@ func (m *AuthModel) View() string {
 		s.WriteString(" ")
 		s.WriteString(styles.Subtle.Render("Connecting..."))
 		s.WriteString("\n")
+		if m.deviceCodePrompt != nil {
+			m.viewDeviceCodePrompt(&s)
+		}
 	} else {
-		if m.inConnectionStringMode {
+		if m.inProfileMode {
+			m.viewProfileSelection(&s)
+		} else if m.inProfileNameMode {
+			m.viewProfileNameInput(&s)
+		} else if m.inSavedConnectionMode {
+			m.viewSavedConnectionSelection(&s)
+		} else if m.inStoreMode {
+			m.viewStoreSelection(&s)
+		} else if m.inPassphraseMode {
+			m.viewPassphraseInput(&s)
+		} else if m.inConnectionStringNameMode {
+			m.viewConnectionStringNameInput(&s)
+		} else if m.inConnectionStringMode {
 			m.viewConnectionStringInput(&s)
+		} else if m.inServicePrincipalMode {
+			m.viewServicePrincipalInput(&s)
+		} else if m.inClientIDMode {
+			m.viewClientIDInput(&s)
 		} else if m.inNamespaceMode {
 			m.viewNamespaceSelection(&s)
 		} else {
@@ -240,7 +448,7 @@ func (m *AuthModel) View() string {
 }
 
 func (m *AuthModel) viewNamespaceSelection(s *strings.Builder) {
-	s.WriteString(styles.Subtle.Render("Select Namespace"))
+	s.WriteString(styles.Subtle.Render("Select Namespace (ctrl+s: save as profile)"))
 	s.WriteString("\n\n")
 
 	maxLines := m.height
@@ -277,7 +485,11 @@ func (m *AuthModel) viewNamespaceSelection(s *strings.Builder) {
 }
 
 func (m *AuthModel) viewAuthSelection(s *strings.Builder) {
-	s.WriteString(styles.Subtle.Render("Select Authentication Method"))
+	title := "Select Authentication Method"
+	if cloud := azure.CurrentCloud(); cloud.Name != azure.CloudPublic {
+		title += " (" + cloud.Name + " cloud)"
+	}
+	s.WriteString(styles.Subtle.Render(title))
 	s.WriteString("\n\n")
 
 	for i, opt := range m.authOptions {
@@ -301,7 +513,7 @@ func (m *AuthModel) viewAuthSelection(s *strings.Builder) {
 }
 
 func (m *AuthModel) viewConnectionStringInput(s *strings.Builder) {
-	s.WriteString(styles.Subtle.Render("Enter Connection String"))
+	s.WriteString(styles.Subtle.Render("Enter Connection String (ctrl+s: save and connect)"))
 	s.WriteString("\n\n")
 	s.WriteString(m.connectionStringInput.View())
 	s.WriteString("\n")
@@ -315,6 +527,8 @@ type ErrorMsg string
 
 func (m *AuthModel) authenticateAndListNamespacesCmd() tea.Cmd {
 	credType := m.selectedCredentialType()
+	sp := m.servicePrincipal()
+	mi := m.managedIdentity()
 	return func() tea.Msg {
 		ctx, cancel := context.WithTimeout(context.Background(), authContextTimeout)
 		defer cancel()
@@ -322,9 +536,14 @@ func (m *AuthModel) authenticateAndListNamespacesCmd() tea.Cmd {
 		var namespaces []azure.NamespaceInfo
 		var err error
 
-		if credType == AzureCLI {
+		switch credType {
+		case AzureCLI:
 			namespaces, err = azure.GetNamespacesForAzureCLI(ctx)
-		} else {
+		case ServicePrincipalSecret, ServicePrincipalCertificate:
+			namespaces, err = azure.GetNamespacesForServicePrincipal(ctx, sp)
+		case ManagedIdentitySystem, ManagedIdentityUser, WorkloadIdentity, EnvironmentOrManagedIdentity:
+			namespaces, err = azure.GetNamespacesForManagedIdentity(ctx, mi)
+		default:
 			namespaces, err = azure.GetNamespacesForInteractiveBrowser(ctx)
 		}
 
@@ -338,13 +557,24 @@ func (m *AuthModel) authenticateAndListNamespacesCmd() tea.Cmd {
 
 func (m *AuthModel) connectWithNamespaceCmd(namespace string) tea.Cmd {
 	credType := m.selectedCredentialType()
+	sp := m.servicePrincipal()
+	deviceCode := m.deviceCode
+	mi := m.managedIdentity()
+	subscriptionID, resourceGroup := m.namespaceResource(namespace)
 	return func() tea.Msg {
 		var client *azure.ServiceBusClient
 		var err error
 
-		if credType == AzureCLI {
+		switch credType {
+		case AzureCLI:
 			client, err = azure.NewServiceBusClientFromAzureCLI(namespace)
-		} else {
+		case ServicePrincipalSecret, ServicePrincipalCertificate:
+			client, err = azure.NewServiceBusClientFromServicePrincipal(sp, namespace)
+		case DeviceCode:
+			client, err = azure.NewServiceBusClientFromDeviceCode(deviceCode, namespace)
+		case ManagedIdentitySystem, ManagedIdentityUser, WorkloadIdentity, EnvironmentOrManagedIdentity:
+			client, err = azure.NewServiceBusClientFromManagedIdentity(mi, namespace)
+		default:
 			client, err = azure.NewServiceBusClientFromInteractiveBrowser(namespace)
 		}
 
@@ -352,6 +582,8 @@ func (m *AuthModel) connectWithNamespaceCmd(namespace string) tea.Cmd {
 			return ErrorMsg(fmt.Sprintf("failed to connect: %v", err))
 		}
 
+		client.SetResource(subscriptionID, resourceGroup)
+
 		log.Printf("authenticated and connected to namespace: %s", namespace)
 		return NamespaceConnectedMsg{
 			Namespace: namespace,
@@ -360,6 +592,20 @@ func (m *AuthModel) connectWithNamespaceCmd(namespace string) tea.Cmd {
 	}
 }
 
+// namespaceResource returns the subscription and resource group of namespace, when it was listed or saved in the
+// profile being connected.
+func (m *AuthModel) namespaceResource(namespace string) (string, string) {
+	for _, ns := range m.namespaces {
+		if ns.Name == namespace && ns.ResourceGroup != "Unknown" {
+			return ns.Subscription, ns.ResourceGroup
+		}
+	}
+	if m.profile != nil && m.profile.Namespace == namespace {
+		return m.profile.Subscription, m.profile.ResourceGroup
+	}
+	return "", ""
+}
+
 func (m *AuthModel) connectWithConnectionStringCmd(connectionString string) tea.Cmd {
 	return func() tea.Msg {
 		client, err := azure.NewServiceBusClientFromConnectionString(connectionString)
@@ -368,6 +614,9 @@ func (m *AuthModel) connectWithConnectionStringCmd(connectionString string) tea.
 		}
 
 		namespace := client.GetNamespace()
+		if client.IsEmulator() {
+			namespace += " (emulator)"
+		}
 
 		log.Printf("connected to namespace via connection string: %s", namespace)
 		return NamespaceConnectedMsg{
@@ -0,0 +1,368 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/MonsieurTib/service-bus-tui/internal/config"
+	"github.com/MonsieurTib/service-bus-tui/internal/styles"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// connectionStringStores are the places a connection string can be saved in when a keyring is available.
+var connectionStringStores = []struct {
+	store, description string
+}{
+	{config.StoreKeyring, "System keyring"},
+	{config.StoreEncryptedFile, "File encrypted with a passphrase"},
+}
+
+func newConnectionStringNameInput() textinput.Model {
+	ti := textinput.New()
+	ti.Placeholder = "name, e.g. dev or emulator"
+	ti.Width = 40
+	return ti
+}
+
+func newPassphraseInput() textinput.Model {
+	ti := textinput.New()
+	ti.EchoMode = textinput.EchoPassword
+	ti.EchoCharacter = '*'
+	ti.Width = 40
+	return ti
+}
+
+// openConnectionStrings shows the saved connection strings, or the connection string input when none is saved.
+func (m *AuthModel) openConnectionStrings() tea.Cmd {
+	saved, err := config.LoadSavedConnectionStrings()
+	if err != nil {
+		m.errMsg = err.Error()
+	}
+	m.savedConnectionStrings = saved
+	if len(saved) == 0 {
+		return m.openConnectionStringInput()
+	}
+	m.inSavedConnectionMode = true
+	m.selectedSavedIdx = 0
+	return nil
+}
+
+func (m *AuthModel) openConnectionStringInput() tea.Cmd {
+	m.inConnectionStringMode = true
+	m.connectionStringInput.Focus()
+	return textinput.Blink
+}
+
+func (m *AuthModel) updateSavedConnectionSelection(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.confirmDeleteSaved {
+		m.confirmDeleteSaved = false
+		if msg.String() == "y" {
+			m.deleteSelectedConnectionString()
+		}
+		return m, nil
+	}
+
+	// The last line enters a new connection string.
+	switch msg.String() {
+	case "up", "k":
+		if m.selectedSavedIdx > 0 {
+			m.selectedSavedIdx--
+		}
+	case "down", "j":
+		if m.selectedSavedIdx < len(m.savedConnectionStrings) {
+			m.selectedSavedIdx++
+		}
+	case "d":
+		if m.selectedSavedIdx < len(m.savedConnectionStrings) {
+			m.confirmDeleteSaved = true
+		}
+	case "enter":
+		m.errMsg = ""
+		m.inSavedConnectionMode = false
+		if m.selectedSavedIdx == len(m.savedConnectionStrings) {
+			return m, m.openConnectionStringInput()
+		}
+
+		saved := m.savedConnectionStrings[m.selectedSavedIdx]
+		if saved.Store == config.StoreEncryptedFile {
+			m.pendingConnectionString = &saved
+			return m, m.openPassphraseInput()
+		}
+		m.isAuthenticating = true
+		return m, tea.Batch(m.spinner.Tick, m.connectWithSavedConnectionStringCmd(saved, ""))
+	}
+	return m, nil
+}
+
+func (m *AuthModel) deleteSelectedConnectionString() {
+	saved := m.savedConnectionStrings[m.selectedSavedIdx]
+	if err := config.DeleteConnectionString(saved); err != nil {
+		m.errMsg = err.Error()
+		return
+	}
+	log.Printf("deleted saved connection string %s", saved.Name)
+
+	m.savedConnectionStrings = append(m.savedConnectionStrings[:m.selectedSavedIdx], m.savedConnectionStrings[m.selectedSavedIdx+1:]...)
+	if len(m.savedConnectionStrings) == 0 {
+		m.inSavedConnectionMode = false
+	}
+}
+
+func (m *AuthModel) openConnectionStringNameInput() tea.Cmd {
+	if strings.TrimSpace(m.connectionStringInput.Value()) == "" {
+		m.errMsg = "connection string cannot be empty"
+		return nil
+	}
+	m.errMsg = ""
+	m.inConnectionStringNameMode = true
+	m.connectionStringNameInput.SetValue("")
+	return m.connectionStringNameInput.Focus()
+}
+
+// KeyringCheckedMsg tells whether the system keyring can be used to save the pending connection string.
+type KeyringCheckedMsg struct {
+	Available bool
+}
+
+// checkKeyringCmd probes the keyring off the update loop: without a Secret Service provider the probe can wait for
+// the D-Bus timeout.
+func checkKeyringCmd() tea.Cmd {
+	return func() tea.Msg {
+		return KeyringCheckedMsg{Available: config.KeyringAvailable()}
+	}
+}
+
+// updateConnectionStringNameInput checks whether a keyring is available once the name is entered.
+func (m *AuthModel) updateConnectionStringNameInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.checkingKeyring {
+		return m, nil
+	}
+	if msg.String() != "enter" {
+		var cmd tea.Cmd
+		m.connectionStringNameInput, cmd = m.connectionStringNameInput.Update(msg)
+		return m, cmd
+	}
+
+	name := strings.TrimSpace(m.connectionStringNameInput.Value())
+	if name == "" {
+		m.errMsg = "name cannot be empty"
+		return m, nil
+	}
+	m.errMsg = ""
+	m.checkingKeyring = true
+	m.pendingConnectionString = &config.SavedConnectionString{Name: name, Store: config.StoreEncryptedFile}
+	return m, tea.Batch(m.spinner.Tick, checkKeyringCmd())
+}
+
+// handleKeyringChecked asks where to save the connection string when a keyring is available, or for the passphrase
+// of the encrypted file when there is none.
+func (m *AuthModel) handleKeyringChecked(msg KeyringCheckedMsg) tea.Cmd {
+	if !m.checkingKeyring {
+		return nil // the name input was left meanwhile
+	}
+	m.checkingKeyring = false
+	m.inConnectionStringNameMode = false
+	if !msg.Available {
+		return m.openPassphraseInput()
+	}
+	m.inStoreMode = true
+	m.selectedStoreIdx = 0
+	return nil
+}
+
+// updateStoreSelection saves the connection string in the keyring and connects with it, or asks for the passphrase
+// of the encrypted file.
+func (m *AuthModel) updateStoreSelection(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "up", "k":
+		if m.selectedStoreIdx > 0 {
+			m.selectedStoreIdx--
+		}
+	case "down", "j":
+		if m.selectedStoreIdx < len(connectionStringStores)-1 {
+			m.selectedStoreIdx++
+		}
+	case "enter":
+		m.inStoreMode = false
+		m.pendingConnectionString.Store = connectionStringStores[m.selectedStoreIdx].store
+		if m.pendingConnectionString.Store == config.StoreEncryptedFile {
+			return m, m.openPassphraseInput()
+		}
+
+		saved := *m.pendingConnectionString
+		m.pendingConnectionString = nil
+		m.inConnectionStringMode = false
+		m.isAuthenticating = true
+		return m, tea.Batch(m.spinner.Tick, m.saveAndConnectCmd(saved, strings.TrimSpace(m.connectionStringInput.Value()), ""))
+	}
+	return m, nil
+}
+
+func (m *AuthModel) openPassphraseInput() tea.Cmd {
+	m.inPassphraseMode = true
+	m.confirmingPassphrase = false
+	m.passphraseInput.SetValue("")
+	m.passphraseConfirmInput.SetValue("")
+	m.passphraseConfirmInput.Blur()
+	return m.passphraseInput.Focus()
+}
+
+// updatePassphraseInput decrypts the selected connection string, or encrypts the one being saved once its
+// passphrase has been typed twice.
+func (m *AuthModel) updatePassphraseInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if msg.String() != "enter" {
+		var cmd tea.Cmd
+		if m.confirmingPassphrase {
+			m.passphraseConfirmInput, cmd = m.passphraseConfirmInput.Update(msg)
+		} else {
+			m.passphraseInput, cmd = m.passphraseInput.Update(msg)
+		}
+		return m, cmd
+	}
+
+	passphrase := m.passphraseInput.Value()
+	if passphrase == "" {
+		m.errMsg = "passphrase cannot be empty"
+		return m, nil
+	}
+
+	if m.inConnectionStringMode && !m.confirmingPassphrase {
+		m.errMsg = ""
+		m.confirmingPassphrase = true
+		m.passphraseInput.Blur()
+		return m, m.passphraseConfirmInput.Focus()
+	}
+	if m.inConnectionStringMode && m.passphraseConfirmInput.Value() != passphrase {
+		m.errMsg = "passphrases do not match"
+		return m, m.openPassphraseInput()
+	}
+
+	saved := *m.pendingConnectionString
+	m.errMsg = ""
+	m.inPassphraseMode = false
+	m.pendingConnectionString = nil
+	m.confirmingPassphrase = false
+	m.passphraseInput.SetValue("")
+	m.passphraseConfirmInput.SetValue("")
+	m.isAuthenticating = true
+
+	if m.inConnectionStringMode {
+		m.inConnectionStringMode = false
+		connStr := strings.TrimSpace(m.connectionStringInput.Value())
+		return m, tea.Batch(m.spinner.Tick, m.saveAndConnectCmd(saved, connStr, passphrase))
+	}
+	return m, tea.Batch(m.spinner.Tick, m.connectWithSavedConnectionStringCmd(saved, passphrase))
+}
+
+func (m *AuthModel) saveAndConnectCmd(saved config.SavedConnectionString, connectionString, passphrase string) tea.Cmd {
+	connect := m.connectWithConnectionStringCmd(connectionString)
+	return func() tea.Msg {
+		if err := config.SaveConnectionString(saved.Name, connectionString, saved.Store, passphrase); err != nil {
+			return ErrorMsg(fmt.Sprintf("failed to save connection string: %v", err))
+		}
+		log.Printf("saved connection string %s in %s", saved.Name, saved.Store)
+		return connect()
+	}
+}
+
+func (m *AuthModel) connectWithSavedConnectionStringCmd(saved config.SavedConnectionString, passphrase string) tea.Cmd {
+	return func() tea.Msg {
+		connStr, err := config.LoadConnectionString(saved, passphrase)
+		if errors.Is(err, config.ErrWrongPassphrase) {
+			return ErrorMsg(fmt.Sprintf("wrong passphrase for %s", saved.Name))
+		}
+		if err != nil {
+			return ErrorMsg(err.Error())
+		}
+		return m.connectWithConnectionStringCmd(connStr)()
+	}
+}
+
+func (m *AuthModel) viewSavedConnectionSelection(s *strings.Builder) {
+	s.WriteString(styles.Subtle.Render("Select Connection String"))
+	s.WriteString("\n\n")
+
+	for i, saved := range m.savedConnectionStrings {
+		store := "keyring"
+		if saved.Store == config.StoreEncryptedFile {
+			store = "encrypted file"
+		}
+		display := fmt.Sprintf("%-20s (%s)", saved.Name, store)
+
+		if i == m.selectedSavedIdx {
+			s.WriteString(styles.Selected.Render("▶ " + display))
+		} else {
+			s.WriteString("  " + display)
+		}
+		s.WriteString("\n")
+	}
+
+	if m.selectedSavedIdx == len(m.savedConnectionStrings) {
+		s.WriteString(styles.Selected.Render("▶ Enter a connection string..."))
+	} else {
+		s.WriteString("  Enter a connection string...")
+	}
+	s.WriteString("\n")
+
+	s.WriteString("\n")
+	if m.confirmDeleteSaved {
+		s.WriteString(styles.Error.Render(fmt.Sprintf("Delete connection string %s? (y/n)", m.savedConnectionStrings[m.selectedSavedIdx].Name)))
+	} else {
+		s.WriteString(styles.Subtle.Render("d: delete saved connection string"))
+	}
+	s.WriteString("\n")
+}
+
+func (m *AuthModel) viewConnectionStringNameInput(s *strings.Builder) {
+	s.WriteString(styles.Subtle.Render("Save Connection String"))
+	s.WriteString("\n\n")
+	s.WriteString(styles.Label.Render(fmt.Sprintf("%-15s", "Name:")))
+	s.WriteString(" ")
+	s.WriteString(m.connectionStringNameInput.View())
+	s.WriteString("\n")
+	if m.checkingKeyring {
+		s.WriteString("\n")
+		s.WriteString(m.spinner.View())
+		s.WriteString(" ")
+		s.WriteString(styles.Subtle.Render("Checking the system keyring..."))
+		s.WriteString("\n")
+	}
+}
+
+func (m *AuthModel) viewStoreSelection(s *strings.Builder) {
+	s.WriteString(styles.Subtle.Render("Save " + m.pendingConnectionString.Name + " in"))
+	s.WriteString("\n\n")
+
+	for i, store := range connectionStringStores {
+		if i == m.selectedStoreIdx {
+			s.WriteString(styles.Selected.Render("▶ " + store.description))
+		} else {
+			s.WriteString("  " + store.description)
+		}
+		s.WriteString("\n")
+	}
+}
+
+func (m *AuthModel) viewPassphraseInput(s *strings.Builder) {
+	name := m.pendingConnectionString.Name
+	if m.inConnectionStringMode {
+		s.WriteString(styles.Subtle.Render(name + " is saved in a file encrypted with a passphrase"))
+	} else {
+		s.WriteString(styles.Subtle.Render("Passphrase of " + name))
+	}
+	s.WriteString("\n\n")
+	s.WriteString(styles.Label.Render(fmt.Sprintf("%-15s", "Passphrase:")))
+	s.WriteString(" ")
+	s.WriteString(m.passphraseInput.View())
+	s.WriteString("\n")
+
+	if m.inConnectionStringMode {
+		s.WriteString(styles.Label.Render(fmt.Sprintf("%-15s", "Confirm:")))
+		s.WriteString(" ")
+		s.WriteString(m.passphraseConfirmInput.View())
+		s.WriteString("\n")
+	}
+}
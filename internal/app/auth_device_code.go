@@ -0,0 +1,108 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/MonsieurTib/service-bus-tui/internal/azure"
+	"github.com/MonsieurTib/service-bus-tui/internal/styles"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/muesli/reflow/wordwrap"
+)
+
+// deviceCodeLoginTimeout leaves the user time to open the verification URL and enter the code.
+const deviceCodeLoginTimeout = 10 * time.Minute
+
+// DeviceCodePromptMsg shows the code to enter while the device code login waits for it.
+type DeviceCodePromptMsg struct {
+	Prompt azure.DeviceCodePrompt
+}
+
+// startDeviceCodeLogin signs in with a device code, in the tenant of the profile or of AZURE_TENANT_ID when it is
+// set, and lists the namespaces once the user has entered the code.
+func (m *AuthModel) startDeviceCodeLogin() tea.Cmd {
+	prompts := make(chan azure.DeviceCodePrompt, 1)
+	cred, err := azure.NewDeviceCodeCredential(m.deviceCodeTenantID(), func(p azure.DeviceCodePrompt) {
+		// Never block the credential: a prompt nobody waits for is dropped.
+		select {
+		case prompts <- p:
+		default:
+		}
+	})
+	if err != nil {
+		m.errMsg = err.Error()
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), deviceCodeLoginTimeout)
+	m.deviceCode = cred
+	m.deviceCodePrompt = nil
+	m.cancelDeviceCode = cancel
+	m.isAuthenticating = true
+
+	done := make(chan struct{})
+	return tea.Batch(
+		m.spinner.Tick,
+		deviceCodeLoginCmd(ctx, cancel, cred, done),
+		waitForDeviceCodePrompt(prompts, done),
+	)
+}
+
+// stopDeviceCodeLogin cancels the device code login if it is still waiting, and hides its prompt.
+func (m *AuthModel) stopDeviceCodeLogin() {
+	if m.cancelDeviceCode != nil {
+		m.cancelDeviceCode()
+		m.cancelDeviceCode = nil
+	}
+	m.deviceCodePrompt = nil
+}
+
+// deviceCodeLoginCmd waits for the sign-in until ctx is done. A login cancelled with esc ends without a message.
+func deviceCodeLoginCmd(ctx context.Context, cancel context.CancelFunc, cred *azure.DeviceCodeCredential, done chan struct{}) tea.Cmd {
+	return func() tea.Msg {
+		defer close(done)
+		defer cancel()
+
+		namespaces, err := azure.GetNamespacesForDeviceCode(ctx, cred)
+		if errors.Is(ctx.Err(), context.Canceled) {
+			return nil
+		}
+		if err != nil {
+			return ErrorMsg(fmt.Sprintf("failed to authenticate or list namespaces: %v", err))
+		}
+		return NamespacesLoadedMsg{Namespaces: namespaces}
+	}
+}
+
+// waitForDeviceCodePrompt returns the prompt of the device code login, or nil when the login ended without one.
+func waitForDeviceCodePrompt(prompts chan azure.DeviceCodePrompt, done chan struct{}) tea.Cmd {
+	return func() tea.Msg {
+		select {
+		case p := <-prompts:
+			return DeviceCodePromptMsg{Prompt: p}
+		case <-done:
+			return nil
+		}
+	}
+}
+
+func (m *AuthModel) viewDeviceCodePrompt(s *strings.Builder) {
+	p := m.deviceCodePrompt
+
+	s.WriteString("\n")
+	s.WriteString(detailLabelStyle.Render(fmt.Sprintf("%-15s", "Open:")))
+	s.WriteString(" ")
+	s.WriteString(p.VerificationURL)
+	s.WriteString("\n")
+	s.WriteString(detailLabelStyle.Render(fmt.Sprintf("%-15s", "Enter code:")))
+	s.WriteString(" ")
+	s.WriteString(styles.Selected.Render(p.UserCode))
+	s.WriteString("\n\n")
+	s.WriteString(styles.Subtle.Render(wordwrap.String(p.Message, 80)))
+	s.WriteString("\n\n")
+	s.WriteString(styles.Subtle.Render("Waiting for the sign-in to complete... • esc: cancel • ctrl+c: quit"))
+	s.WriteString("\n")
+}
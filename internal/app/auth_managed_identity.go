@@ -0,0 +1,79 @@
+package app
+
+import (
+	"context"
+	"os"
+	"strings"
+
+	"github.com/MonsieurTib/service-bus-tui/internal/azure"
+	"github.com/MonsieurTib/service-bus-tui/internal/styles"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// ManagedIdentityDetectedMsg offers the identity provided by the environment or the host, e.g. in an AKS pod.
+type ManagedIdentityDetectedMsg struct {
+	Description string
+}
+
+func newClientIDInput() textinput.Model {
+	ti := textinput.New()
+	ti.Placeholder = "client ID of the user-assigned identity"
+	ti.Width = 60
+	ti.SetValue(os.Getenv("AZURE_CLIENT_ID"))
+	return ti
+}
+
+func detectManagedIdentityCmd() tea.Cmd {
+	return func() tea.Msg {
+		description, ok := azure.DetectManagedIdentity(context.Background())
+		if !ok {
+			return nil
+		}
+		return ManagedIdentityDetectedMsg{Description: description}
+	}
+}
+
+// addDetectedIdentity puts the detected identity first, keeping the selected option selected.
+func (m *AuthModel) addDetectedIdentity(description string) {
+	m.authOptions = append([]string{"Environment / Managed Identity (" + description + ")"}, m.authOptions...)
+	m.credentialTypes = append([]CredentialType{EnvironmentOrManagedIdentity}, m.credentialTypes...)
+	m.selectedAuth++
+}
+
+// managedIdentity returns the identity of the selected credential type.
+func (m *AuthModel) managedIdentity() azure.ManagedIdentity {
+	switch m.selectedCredentialType() {
+	case ManagedIdentitySystem:
+		return azure.ManagedIdentity{Kind: azure.ManagedIdentitySystemAssigned}
+	case ManagedIdentityUser:
+		return azure.ManagedIdentity{Kind: azure.ManagedIdentityUserAssigned, ClientID: strings.TrimSpace(m.clientIDInput.Value())}
+	case WorkloadIdentity:
+		return azure.ManagedIdentity{Kind: azure.ManagedIdentityWorkload}
+	}
+	return azure.ManagedIdentity{Kind: azure.ManagedIdentityAuto}
+}
+
+func (m *AuthModel) updateClientIDInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if msg.String() == "enter" {
+		if strings.TrimSpace(m.clientIDInput.Value()) == "" {
+			m.errMsg = "client ID cannot be empty"
+			return m, nil
+		}
+		m.errMsg = ""
+		m.inClientIDMode = false
+		m.isAuthenticating = true
+		return m, tea.Batch(m.spinner.Tick, m.authenticateAndListNamespacesCmd())
+	}
+
+	var cmd tea.Cmd
+	m.clientIDInput, cmd = m.clientIDInput.Update(msg)
+	return m, cmd
+}
+
+func (m *AuthModel) viewClientIDInput(s *strings.Builder) {
+	s.WriteString(styles.Subtle.Render("Enter the Client ID of the User-Assigned Managed Identity"))
+	s.WriteString("\n\n")
+	s.WriteString(m.clientIDInput.View())
+	s.WriteString("\n")
+}
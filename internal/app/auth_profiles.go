@@ -0,0 +1,289 @@
+package app
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/MonsieurTib/service-bus-tui/internal/azure"
+	"github.com/MonsieurTib/service-bus-tui/internal/config"
+	"github.com/MonsieurTib/service-bus-tui/internal/styles"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// profileAuthMethods maps the credential types that can be saved in a profile to their name in the profiles file.
+// Connection strings are secrets, so they have no profile.
+var profileAuthMethods = map[CredentialType]string{
+	AzureCLI:                     config.AuthAzureCLI,
+	InteractiveBrowser:           config.AuthInteractiveBrowser,
+	DeviceCode:                   config.AuthDeviceCode,
+	ServicePrincipalSecret:       config.AuthServicePrincipalSecret,
+	ServicePrincipalCertificate:  config.AuthServicePrincipalCertificate,
+	ManagedIdentitySystem:        config.AuthManagedIdentitySystem,
+	ManagedIdentityUser:          config.AuthManagedIdentityUser,
+	WorkloadIdentity:             config.AuthWorkloadIdentity,
+	EnvironmentOrManagedIdentity: config.AuthEnvironment,
+}
+
+func credentialTypeOfProfile(p config.Profile) (CredentialType, bool) {
+	for credType, auth := range profileAuthMethods {
+		if auth == p.Auth {
+			return credType, true
+		}
+	}
+	return 0, false
+}
+
+func newProfileNameInput() textinput.Model {
+	ti := textinput.New()
+	ti.Placeholder = "profile name"
+	ti.Width = 40
+	return ti
+}
+
+func (m *AuthModel) updateProfileSelection(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.confirmDeleteProfile {
+		m.confirmDeleteProfile = false
+		if msg.String() == "y" {
+			m.deleteSelectedProfile()
+		}
+		return m, nil
+	}
+
+	// The last line starts a new connection.
+	switch msg.String() {
+	case "up", "k":
+		if m.selectedProfileIdx > 0 {
+			m.selectedProfileIdx--
+		}
+	case "down", "j":
+		if m.selectedProfileIdx < len(m.profiles) {
+			m.selectedProfileIdx++
+		}
+	case "d":
+		if m.selectedProfileIdx < len(m.profiles) {
+			m.confirmDeleteProfile = true
+		}
+	case "enter":
+		m.errMsg = ""
+		if m.selectedProfileIdx == len(m.profiles) {
+			m.inProfileMode = false
+			return m, nil
+		}
+		return m, m.connectProfile(m.profiles[m.selectedProfileIdx])
+	}
+	return m, nil
+}
+
+func (m *AuthModel) deleteSelectedProfile() {
+	name := m.profiles[m.selectedProfileIdx].Name
+	if err := config.DeleteProfile(name); err != nil {
+		m.errMsg = err.Error()
+		return
+	}
+	log.Printf("deleted profile %s", name)
+
+	m.profiles = append(m.profiles[:m.selectedProfileIdx], m.profiles[m.selectedProfileIdx+1:]...)
+	if len(m.profiles) == 0 {
+		m.inProfileMode = false
+	}
+}
+
+// connectProfile connects straight to the namespace of p. Credentials that need the user, a device code login or a
+// client secret missing from the environment, are asked for first; the namespace list is then skipped.
+func (m *AuthModel) connectProfile(p config.Profile) tea.Cmd {
+	credType, ok := credentialTypeOfProfile(p)
+	if !ok {
+		m.errMsg = fmt.Sprintf("profile %s has an unknown auth method %q", p.Name, p.Auth)
+		return nil
+	}
+	if p.Cloud != "" {
+		cloud, err := azure.CloudByName(p.Cloud)
+		if err != nil {
+			m.errMsg = fmt.Sprintf("profile %s: %v", p.Name, err)
+			return nil
+		}
+		azure.SetCloud(cloud)
+	}
+
+	m.inProfileMode = false
+	m.profile = &p
+	m.profileCredentialType = credType
+	m.errMsg = ""
+
+	switch credType {
+	case ServicePrincipalSecret, ServicePrincipalCertificate:
+		m.spInputs[spFieldTenantID].SetValue(p.TenantID)
+		m.spInputs[spFieldClientID].SetValue(p.ClientID)
+		if p.CertificatePath != "" {
+			m.spInputs[spFieldCertificatePath].SetValue(p.CertificatePath)
+		}
+		if validateServicePrincipal(m.servicePrincipal()) != nil {
+			return m.openServicePrincipalInput()
+		}
+	case ManagedIdentityUser:
+		m.clientIDInput.SetValue(p.ClientID)
+	case DeviceCode:
+		return m.startDeviceCodeLogin()
+	}
+
+	m.isAuthenticating = true
+	return tea.Batch(m.spinner.Tick, m.connectWithNamespaceCmd(p.Namespace))
+}
+
+// connectListedProfileNamespace connects to the namespace of the profile being connected, once the credentials asked
+// for have listed the namespaces.
+func (m *AuthModel) connectListedProfileNamespace(namespaces []azure.NamespaceInfo) tea.Cmd {
+	for _, ns := range namespaces {
+		if ns.Name == m.profile.Namespace {
+			m.isAuthenticating = true
+			return m.connectWithNamespaceCmd(ns.Name)
+		}
+	}
+
+	err := ErrorMsg(fmt.Sprintf("namespace %s of profile %s was not found", m.profile.Namespace, m.profile.Name))
+	return func() tea.Msg { return err }
+}
+
+// cancelProfile goes back to the profile picker after connecting with a profile failed or was cancelled, and back to
+// the cloud selected on the command line.
+func (m *AuthModel) cancelProfile() {
+	if m.profile == nil {
+		return
+	}
+	azure.SetCloud(m.cloud)
+	m.profile = nil
+	m.inServicePrincipalMode = false
+	m.inNamespaceMode = false
+	m.inProfileMode = len(m.profiles) > 0
+}
+
+// deviceCodeTenantID returns the tenant of the profile being connected, or AZURE_TENANT_ID.
+func (m *AuthModel) deviceCodeTenantID() string {
+	if m.profile != nil && m.profile.TenantID != "" {
+		return m.profile.TenantID
+	}
+	return os.Getenv("AZURE_TENANT_ID")
+}
+
+func (m *AuthModel) openProfileNameInput() tea.Cmd {
+	if _, ok := profileAuthMethods[m.selectedCredentialType()]; !ok {
+		m.errMsg = "this authentication method cannot be saved in a profile"
+		return nil
+	}
+	m.inProfileNameMode = true
+	m.errMsg = ""
+	m.profileNameInput.SetValue(m.namespaces[m.selectedNamespaceIdx].Name)
+	m.profileNameInput.CursorEnd()
+	return m.profileNameInput.Focus()
+}
+
+// updateProfileNameInput saves the selected namespace and the credentials used to list it as a profile, then
+// connects to it.
+func (m *AuthModel) updateProfileNameInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if msg.String() != "enter" {
+		var cmd tea.Cmd
+		m.profileNameInput, cmd = m.profileNameInput.Update(msg)
+		return m, cmd
+	}
+
+	name := strings.TrimSpace(m.profileNameInput.Value())
+	if name == "" {
+		m.errMsg = "profile name cannot be empty"
+		return m, nil
+	}
+
+	p := m.newProfile(name, m.namespaces[m.selectedNamespaceIdx])
+	if err := config.SaveProfile(p); err != nil {
+		m.errMsg = err.Error()
+		return m, nil
+	}
+	log.Printf("saved profile %s", name)
+	if profiles, err := config.LoadProfiles(); err == nil {
+		m.profiles = profiles
+	}
+
+	m.errMsg = ""
+	m.inProfileNameMode = false
+	m.isAuthenticating = true
+	return m, tea.Batch(m.spinner.Tick, m.connectWithNamespaceCmd(p.Namespace))
+}
+
+func (m *AuthModel) newProfile(name string, ns azure.NamespaceInfo) config.Profile {
+	credType := m.selectedCredentialType()
+	p := config.Profile{
+		Name:          name,
+		Auth:          profileAuthMethods[credType],
+		Cloud:         azure.CurrentCloud().Name,
+		Subscription:  ns.Subscription,
+		ResourceGroup: ns.ResourceGroup,
+		Namespace:     ns.Name,
+	}
+
+	switch credType {
+	case ServicePrincipalSecret, ServicePrincipalCertificate:
+		sp := m.servicePrincipal()
+		p.TenantID = sp.TenantID
+		p.ClientID = sp.ClientID
+		p.CertificatePath = sp.CertificatePath
+	case ManagedIdentityUser:
+		p.ClientID = m.managedIdentity().ClientID
+	case DeviceCode:
+		p.TenantID = m.deviceCodeTenantID()
+	}
+	return p
+}
+
+func (m *AuthModel) viewProfileSelection(s *strings.Builder) {
+	s.WriteString(styles.Subtle.Render("Select Profile"))
+	s.WriteString("\n\n")
+
+	for i, p := range m.profiles {
+		subID := p.Subscription
+		if len(subID) > 8 {
+			subID = subID[:8]
+		}
+		display := fmt.Sprintf("%-20s %s (%s", p.Name, p.Namespace, p.Auth)
+		if subID != "" {
+			display += " / " + subID
+		}
+		if p.Cloud != "" && p.Cloud != azure.CloudPublic {
+			display += " / " + p.Cloud
+		}
+		display += ")"
+
+		if i == m.selectedProfileIdx {
+			s.WriteString(styles.Selected.Render("▶ " + display))
+		} else {
+			s.WriteString("  " + display)
+		}
+		s.WriteString("\n")
+	}
+
+	if m.selectedProfileIdx == len(m.profiles) {
+		s.WriteString(styles.Selected.Render("▶ New connection..."))
+	} else {
+		s.WriteString("  New connection...")
+	}
+	s.WriteString("\n")
+
+	s.WriteString("\n")
+	if m.confirmDeleteProfile {
+		s.WriteString(styles.Error.Render(fmt.Sprintf("Delete profile %s? (y/n)", m.profiles[m.selectedProfileIdx].Name)))
+	} else {
+		s.WriteString(styles.Subtle.Render("d: delete profile"))
+	}
+	s.WriteString("\n")
+}
+
+func (m *AuthModel) viewProfileNameInput(s *strings.Builder) {
+	ns := m.namespaces[m.selectedNamespaceIdx]
+	s.WriteString(styles.Subtle.Render("Save " + ns.Name + " as a Profile"))
+	s.WriteString("\n\n")
+	s.WriteString(styles.Label.Render(fmt.Sprintf("%-15s", "Name:")))
+	s.WriteString(" ")
+	s.WriteString(m.profileNameInput.View())
+	s.WriteString("\n")
+}
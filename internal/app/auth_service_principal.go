@@ -0,0 +1,180 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/MonsieurTib/service-bus-tui/internal/azure"
+	"github.com/MonsieurTib/service-bus-tui/internal/styles"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const (
+	spFieldTenantID = iota
+	spFieldClientID
+	spFieldClientSecret
+	spFieldCertificatePath
+	spFieldCertificatePassword
+	spFieldCount
+)
+
+var spFieldLabels = [spFieldCount]string{
+	spFieldTenantID:            "Tenant ID",
+	spFieldClientID:            "Client ID",
+	spFieldClientSecret:        "Client secret",
+	spFieldCertificatePath:     "Certificate",
+	spFieldCertificatePassword: "Password",
+}
+
+var spFieldPlaceholders = [spFieldCount]string{
+	spFieldTenantID:            "directory (tenant) ID",
+	spFieldClientID:            "application (client) ID",
+	spFieldCertificatePath:     "path to a PEM or PKCS#12 file",
+	spFieldCertificatePassword: "optional",
+}
+
+// newServicePrincipalInputs returns the service principal form, pre-filled from the AZURE_* environment variables.
+func newServicePrincipalInputs() [spFieldCount]textinput.Model {
+	env := azure.ServicePrincipalFromEnv()
+	values := [spFieldCount]string{
+		spFieldTenantID:            env.TenantID,
+		spFieldClientID:            env.ClientID,
+		spFieldClientSecret:        env.ClientSecret,
+		spFieldCertificatePath:     env.CertificatePath,
+		spFieldCertificatePassword: env.CertificatePassword,
+	}
+
+	var inputs [spFieldCount]textinput.Model
+	for i := range inputs {
+		ti := textinput.New()
+		ti.Prompt = ""
+		ti.Placeholder = spFieldPlaceholders[i]
+		ti.Width = 60
+		ti.SetValue(values[i])
+		if i == spFieldClientSecret || i == spFieldCertificatePassword {
+			ti.EchoMode = textinput.EchoPassword
+			ti.EchoCharacter = '*'
+		}
+		inputs[i] = ti
+	}
+	return inputs
+}
+
+// servicePrincipalFields returns the fields shown for the selected credential type.
+func (m *AuthModel) servicePrincipalFields() []int {
+	if m.selectedCredentialType() == ServicePrincipalCertificate {
+		return []int{spFieldTenantID, spFieldClientID, spFieldCertificatePath, spFieldCertificatePassword}
+	}
+	return []int{spFieldTenantID, spFieldClientID, spFieldClientSecret}
+}
+
+func (m *AuthModel) openServicePrincipalInput() tea.Cmd {
+	m.inServicePrincipalMode = true
+	m.spFocus = 0
+	// Start at the first field left empty by the environment.
+	for i, field := range m.servicePrincipalFields() {
+		if m.spInputs[field].Value() == "" {
+			m.spFocus = i
+			break
+		}
+	}
+	return m.focusServicePrincipalField()
+}
+
+func (m *AuthModel) focusServicePrincipalField() tea.Cmd {
+	fields := m.servicePrincipalFields()
+	for _, field := range fields {
+		m.spInputs[field].Blur()
+	}
+	return m.spInputs[fields[m.spFocus]].Focus()
+}
+
+func (m *AuthModel) updateServicePrincipalInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	fields := m.servicePrincipalFields()
+
+	switch msg.String() {
+	case "tab", "down":
+		m.spFocus = (m.spFocus + 1) % len(fields)
+		return m, m.focusServicePrincipalField()
+	case "shift+tab", "up":
+		m.spFocus = (m.spFocus - 1 + len(fields)) % len(fields)
+		return m, m.focusServicePrincipalField()
+	case "enter":
+		if m.spFocus < len(fields)-1 {
+			m.spFocus++
+			return m, m.focusServicePrincipalField()
+		}
+
+		sp := m.servicePrincipal()
+		if err := validateServicePrincipal(sp); err != nil {
+			m.errMsg = err.Error()
+			return m, nil
+		}
+		m.errMsg = ""
+		m.inServicePrincipalMode = false
+		m.isAuthenticating = true
+		return m, tea.Batch(m.spinner.Tick, m.authenticateAndListNamespacesCmd())
+	}
+
+	field := fields[m.spFocus]
+	var cmd tea.Cmd
+	m.spInputs[field], cmd = m.spInputs[field].Update(msg)
+	return m, cmd
+}
+
+// servicePrincipal returns the service principal typed in the form, with only the secret or the certificate of the
+// selected credential type.
+func (m *AuthModel) servicePrincipal() azure.ServicePrincipal {
+	value := func(field int) string { return strings.TrimSpace(m.spInputs[field].Value()) }
+
+	sp := azure.ServicePrincipal{
+		TenantID: value(spFieldTenantID),
+		ClientID: value(spFieldClientID),
+	}
+	if m.selectedCredentialType() == ServicePrincipalCertificate {
+		sp.CertificatePath = value(spFieldCertificatePath)
+		// Passwords may legitimately start or end with spaces.
+		sp.CertificatePassword = m.spInputs[spFieldCertificatePassword].Value()
+	} else {
+		sp.ClientSecret = m.spInputs[spFieldClientSecret].Value()
+	}
+	return sp
+}
+
+func validateServicePrincipal(sp azure.ServicePrincipal) error {
+	switch {
+	case sp.TenantID == "":
+		return fmt.Errorf("tenant ID cannot be empty")
+	case sp.ClientID == "":
+		return fmt.Errorf("client ID cannot be empty")
+	case sp.ClientSecret == "" && sp.CertificatePath == "":
+		return fmt.Errorf("client secret or certificate path cannot be empty")
+	}
+	return nil
+}
+
+func (m *AuthModel) viewServicePrincipalInput(s *strings.Builder) {
+	title := "Service Principal (client secret)"
+	if m.selectedCredentialType() == ServicePrincipalCertificate {
+		title = "Service Principal (certificate)"
+	}
+	s.WriteString(styles.Subtle.Render(title))
+	s.WriteString("\n\n")
+
+	for i, field := range m.servicePrincipalFields() {
+		label := fmt.Sprintf("%-15s", spFieldLabels[field]+":")
+		if i == m.spFocus {
+			s.WriteString(styles.Label.Render(label))
+		} else {
+			s.WriteString(detailLabelStyle.Render(label))
+		}
+		s.WriteString(" ")
+		s.WriteString(m.spInputs[field].View())
+		s.WriteString("\n")
+	}
+
+	s.WriteString("\n")
+	s.WriteString(styles.Subtle.Render("Pre-filled from AZURE_TENANT_ID, AZURE_CLIENT_ID, AZURE_CLIENT_SECRET and AZURE_CLIENT_CERTIFICATE_PATH when set"))
+	s.WriteString("\n")
+}
@@ -0,0 +1,292 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/MonsieurTib/service-bus-tui/internal/azure"
+	"github.com/MonsieurTib/service-bus-tui/internal/styles"
+	"github.com/charmbracelet/bubbles/spinner"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/muesli/reflow/wordwrap"
+)
+
+const scheduledTimeLayout = "2006-01-02 15:04:05"
+
+const (
+	fieldBody = iota
+	fieldContentType
+	fieldSubject
+	fieldMessageID
+	fieldCorrelationID
+	fieldSessionID
+	fieldTimeToLive
+	fieldScheduledEnqueueTime
+	fieldProperties
+	fieldCount
+)
+
+var composerLabels = [fieldCount]string{
+	fieldBody:                 "Body",
+	fieldContentType:          "Content-Type",
+	fieldSubject:              "Subject",
+	fieldMessageID:            "Message ID",
+	fieldCorrelationID:        "Correlation ID",
+	fieldSessionID:            "Session ID",
+	fieldTimeToLive:           "TTL",
+	fieldScheduledEnqueueTime: "Scheduled",
+	fieldProperties:           "Properties",
+}
+
+var composerPlaceholders = [fieldCount]string{
+	fieldBody:                 `{"hello": "world"}`,
+	fieldContentType:          "application/json",
+	fieldTimeToLive:           "e.g. 10m, 24h",
+	fieldScheduledEnqueueTime: scheduledTimeLayout + " or RFC 3339",
+	fieldProperties:           "key=value; other=42",
+}
+
+// ComposerModel edits a new message and sends it to a topic or queue.
+type ComposerModel struct {
+	client     *azure.ServiceBusClient
+	entityName string
+	inputs     [fieldCount]textinput.Model
+	focusIdx   int
+	spinner    spinner.Model
+	isSending  bool
+	isEditing  bool
+	sentCount  int
+	errMsg     string
+	width      int
+	height     int
+}
+
+// ComposeSelectedMsg opens the composer for a topic or queue.
+type ComposeSelectedMsg struct {
+	EntityName string
+}
+
+type ComposerClosedMsg struct{}
+
+type MessageSentMsg struct {
+	EntityName string
+	Err        error
+}
+
+func NewComposerModel(client *azure.ServiceBusClient, entityName string) *ComposerModel {
+	s := spinner.New()
+	s.Spinner = spinner.MiniDot
+
+	m := &ComposerModel{
+		client:     client,
+		entityName: entityName,
+		spinner:    s,
+	}
+
+	for i := range m.inputs {
+		ti := textinput.New()
+		ti.Prompt = ""
+		ti.Placeholder = composerPlaceholders[i]
+		m.inputs[i] = ti
+	}
+	m.inputs[fieldBody].Focus()
+
+	return m
+}
+
+func (m *ComposerModel) Init() tea.Cmd {
+	return textinput.Blink
+}
+
+func (m *ComposerModel) Update(msg tea.Msg) (*ComposerModel, tea.Cmd) {
+	var spinnerCmd tea.Cmd
+	m.spinner, spinnerCmd = m.spinner.Update(msg)
+
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		if m.isSending {
+			return m, nil
+		}
+
+		switch msg.String() {
+		case "esc":
+			return m, func() tea.Msg { return ComposerClosedMsg{} }
+		case "tab", "down", "enter":
+			return m, m.focusField(m.focusIdx + 1)
+		case "shift+tab", "up":
+			return m, m.focusField(m.focusIdx - 1)
+		case "ctrl+s":
+			return m, m.send()
+		case "ctrl+e":
+			// Invalid fields are left out of the draft; the editor is where they get fixed.
+			draft, _ := m.buildMessage()
+			m.errMsg = ""
+			m.isEditing = true
+			return m, editMessageCmd(draft)
+		}
+
+		var cmd tea.Cmd
+		m.inputs[m.focusIdx], cmd = m.inputs[m.focusIdx].Update(msg)
+		return m, cmd
+
+	case MessageEditedMsg:
+		if !m.isEditing {
+			break
+		}
+		m.isEditing = false
+		switch {
+		case msg.Err != nil:
+			m.errMsg = msg.Err.Error()
+		case !msg.Cancelled:
+			m.isSending = true
+			return m, tea.Batch(m.spinner.Tick, m.sendCmd(msg.Message))
+		}
+
+	case MessageSentMsg:
+		m.isSending = false
+		if msg.Err != nil {
+			m.errMsg = fmt.Sprintf("failed to send message: %v", msg.Err)
+		} else {
+			m.sentCount++
+		}
+	}
+
+	if m.isSending {
+		return m, spinnerCmd
+	}
+
+	var cmd tea.Cmd
+	m.inputs[m.focusIdx], cmd = m.inputs[m.focusIdx].Update(msg)
+	return m, cmd
+}
+
+func (m *ComposerModel) focusField(idx int) tea.Cmd {
+	m.inputs[m.focusIdx].Blur()
+	m.focusIdx = (idx + fieldCount) % fieldCount
+	return m.inputs[m.focusIdx].Focus()
+}
+
+func (m *ComposerModel) SetSize(width, height int) {
+	m.width = width
+	m.height = height
+
+	// Reserve the label column (15) and its separator (1).
+	for i := range m.inputs {
+		m.inputs[i].Width = max(width-17, 10)
+	}
+}
+
+func (m *ComposerModel) send() tea.Cmd {
+	msg, err := m.buildMessage()
+	if err != nil {
+		m.errMsg = err.Error()
+		return nil
+	}
+
+	m.errMsg = ""
+	m.isSending = true
+	return tea.Batch(m.spinner.Tick, m.sendCmd(msg))
+}
+
+// buildMessage validates the fields and converts them into a message.
+func (m *ComposerModel) buildMessage() (azure.MessageInfo, error) {
+	value := func(field int) string {
+		return strings.TrimSpace(m.inputs[field].Value())
+	}
+
+	msg := azure.MessageInfo{
+		Body:          m.inputs[fieldBody].Value(),
+		ContentType:   value(fieldContentType),
+		Subject:       value(fieldSubject),
+		MessageID:     value(fieldMessageID),
+		CorrelationID: value(fieldCorrelationID),
+		SessionID:     value(fieldSessionID),
+	}
+
+	if ttl := value(fieldTimeToLive); ttl != "" {
+		d, err := time.ParseDuration(ttl)
+		if err != nil || d <= 0 {
+			return msg, fmt.Errorf("invalid TTL: %q", ttl)
+		}
+		msg.TimeToLive = d
+	}
+
+	if scheduled := value(fieldScheduledEnqueueTime); scheduled != "" {
+		t, err := parseScheduledTime(scheduled)
+		if err != nil {
+			return msg, err
+		}
+		msg.ScheduledEnqueueTime = t
+	}
+
+	props, err := parseProperties(value(fieldProperties))
+	if err != nil {
+		return msg, err
+	}
+	msg.Properties = props
+
+	return msg, nil
+}
+
+func parseScheduledTime(s string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation(scheduledTimeLayout, s, time.Local); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid scheduled time: %q", s)
+}
+
+func (m *ComposerModel) View() string {
+	var s strings.Builder
+
+	s.WriteString(detailHeaderStyle.Render("Send message to " + m.entityName))
+	s.WriteString("\n")
+	s.WriteString(detailSeparator)
+	s.WriteString("\n\n")
+
+	for i, input := range m.inputs {
+		label := fmt.Sprintf("%-15s", composerLabels[i]+":")
+		if i == m.focusIdx {
+			s.WriteString(styles.Label.Render(label))
+		} else {
+			s.WriteString(detailLabelStyle.Render(label))
+		}
+		s.WriteString(" ")
+		s.WriteString(input.View())
+		s.WriteString("\n")
+	}
+
+	s.WriteString("\n")
+	switch {
+	case m.isSending:
+		s.WriteString(m.spinner.View())
+		s.WriteString(" ")
+		s.WriteString(styles.Subtle.Render("Sending..."))
+	case m.errMsg != "":
+		s.WriteString(styles.Error.Render(wordwrap.String(m.errMsg, max(m.width, 10))))
+	case m.sentCount > 0:
+		s.WriteString(styles.Selected.Render(fmt.Sprintf("✓ Message sent to %s (%d so far)", m.entityName, m.sentCount)))
+	}
+	s.WriteString("\n\n")
+	s.WriteString(styles.Subtle.Render("tab/↑↓: next field • ctrl+s: send • ctrl+e: edit in $EDITOR • esc: close"))
+
+	return s.String()
+}
+
+func (m *ComposerModel) sendCmd(msg azure.MessageInfo) tea.Cmd {
+	client := m.client
+	entityName := m.entityName
+
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), defaultContextTimeout)
+		defer cancel()
+
+		err := client.SendMessage(ctx, entityName, msg)
+		return MessageSentMsg{EntityName: entityName, Err: err}
+	}
+}
@@ -0,0 +1,161 @@
+package app
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/MonsieurTib/service-bus-tui/internal/azure"
+	"github.com/MonsieurTib/service-bus-tui/internal/styles"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/muesli/reflow/truncate"
+)
+
+// correlationBuilder picks the application properties of a message to copy into a new correlation rule. The
+// correlation ID, subject and content type are always copied, and can be cleared in the rule form.
+type correlationBuilder struct {
+	message  azure.MessageInfo
+	keys     []string // application property names, sorted
+	selected map[string]bool
+	cursor   int
+}
+
+// CorrelationRuleDraftedMsg asks for the subscription in which Rule is created.
+type CorrelationRuleDraftedMsg struct {
+	Rule azure.RuleInfo
+}
+
+// openCorrelationBuilder starts building a correlation rule from the selected message. When it has no application
+// properties, there is nothing to pick and the rule is drafted right away.
+func (m *MessagesModel) openCorrelationBuilder() tea.Cmd {
+	selected := m.SelectedMessage()
+	if selected == nil {
+		return nil
+	}
+
+	b := &correlationBuilder{message: *selected, selected: make(map[string]bool)}
+	for k := range selected.Properties {
+		b.keys = append(b.keys, k)
+	}
+	slices.Sort(b.keys)
+
+	if len(b.keys) == 0 {
+		return b.draft()
+	}
+	m.builder = b
+	return nil
+}
+
+func (m *MessagesModel) updateCorrelationBuilder(msg tea.KeyMsg) tea.Cmd {
+	b := m.builder
+
+	switch msg.String() {
+	case "esc":
+		m.builder = nil
+	case "up", "k":
+		if b.cursor > 0 {
+			b.cursor--
+		}
+	case "down", "j":
+		if b.cursor < len(b.keys)-1 {
+			b.cursor++
+		}
+	case " ", "x":
+		key := b.keys[b.cursor]
+		b.selected[key] = !b.selected[key]
+	case "a":
+		// Select all unless they are all selected already.
+		all := slices.ContainsFunc(b.keys, func(key string) bool { return !b.selected[key] })
+		for _, key := range b.keys {
+			b.selected[key] = all
+		}
+	case "enter":
+		m.builder = nil
+		return b.draft()
+	}
+	return nil
+}
+
+// draft returns the correlation rule matching the message on its correlation ID, subject, content type and the
+// selected application properties.
+func (b *correlationBuilder) draft() tea.Cmd {
+	msg := b.message
+	rule := azure.RuleInfo{
+		FilterType: azure.RuleFilterCorrelation,
+		Correlation: azure.CorrelationFilter{
+			CorrelationID: msg.CorrelationID,
+			Subject:       msg.Subject,
+			ContentType:   msg.ContentType,
+		},
+	}
+
+	for _, key := range b.keys {
+		if !b.selected[key] {
+			continue
+		}
+		if rule.Correlation.Properties == nil {
+			rule.Correlation.Properties = make(map[string]any)
+		}
+		rule.Correlation.Properties[key] = msg.Properties[key]
+	}
+
+	return func() tea.Msg { return CorrelationRuleDraftedMsg{Rule: rule} }
+}
+
+func (m *MessagesModel) viewCorrelationBuilder() string {
+	b := m.builder
+	var s strings.Builder
+
+	s.WriteString(detailHeaderStyle.Render("New correlation rule from message " + b.message.MessageID))
+	s.WriteString("\n\n")
+
+	fixed := []struct{ label, value string }{
+		{"Correlation ID", b.message.CorrelationID},
+		{"Subject", b.message.Subject},
+		{"Content-Type", b.message.ContentType},
+	}
+	for _, f := range fixed {
+		value := f.value
+		if value == "" {
+			value = styles.Subtle.Render("(not set)")
+		}
+		s.WriteString(detailLabelStyle.Render(fmt.Sprintf("%-15s", f.label+":")))
+		s.WriteString(" ")
+		s.WriteString(value)
+		s.WriteString("\n")
+	}
+
+	s.WriteString("\n")
+	s.WriteString(styles.Label.Render("Application properties to match:"))
+	s.WriteString("\n")
+
+	// Reserve: title (1) + blank (1) + fixed fields (3) + blank and label (2) + blank and footer (2)
+	visible := max(m.height-9, 3)
+	start := max(b.cursor-visible+1, 0)
+	end := min(start+visible, len(b.keys))
+
+	for i := start; i < end; i++ {
+		key := b.keys[i]
+		check := "[ ]"
+		if b.selected[key] {
+			check = "[x]"
+		}
+
+		// Written as in the rule form, so the type the rule will match is visible, e.g. "123" rather than 123.
+		line := fmt.Sprintf("%s %s", check, formatProperties(map[string]any{key: b.message.Properties[key]}))
+		if m.width > 2 {
+			line = truncate.StringWithTail(line, uint(m.width-2), "…")
+		}
+
+		if i == b.cursor {
+			s.WriteString(styles.Selected.Render("▶ " + line))
+		} else {
+			s.WriteString("  " + line)
+		}
+		s.WriteString("\n")
+	}
+
+	s.WriteString("\n")
+	s.WriteString(styles.Subtle.Render("↑↓: navigate • space: toggle • a: toggle all • enter: pick subscription • esc: cancel"))
+	return s.String()
+}
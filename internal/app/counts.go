@@ -0,0 +1,128 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/MonsieurTib/service-bus-tui/internal/azure"
+	"github.com/MonsieurTib/service-bus-tui/internal/styles"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const countsRefreshInterval = 30 * time.Second
+
+// refreshCountsMsg triggers a periodic refresh of the message counts shown in the tree. It carries the client it was
+// scheduled for, so a tick still pending for a previous connection ends its loop instead of running alongside the
+// new one.
+type refreshCountsMsg struct {
+	client *azure.ServiceBusClient
+}
+
+// CountsLoadedMsg carries message counts keyed by entity name ("queue" or "topic/subscription").
+type CountsLoadedMsg struct {
+	Counts map[string]azure.MessageCounts
+	client *azure.ServiceBusClient
+}
+
+func scheduleCountsRefresh(client *azure.ServiceBusClient) tea.Cmd {
+	return tea.Tick(countsRefreshInterval, func(time.Time) tea.Msg {
+		return refreshCountsMsg{client: client}
+	})
+}
+
+// loadedTopics returns the topics whose subscriptions are in the tree.
+func (n *NamespaceModel) loadedTopics() []string {
+	n.cacheMutex.RLock()
+	defer n.cacheMutex.RUnlock()
+
+	topics := make([]string, 0, len(n.subscriptionCache))
+	for topicID := range n.subscriptionCache {
+		topics = append(topics, strings.TrimPrefix(topicID, "topic-"))
+	}
+	return topics
+}
+
+// loadCountsCmd fetches the counts of every queue, when includeQueues is set, and of the subscriptions of topics.
+// Failures are logged and skipped so one missing entity doesn't blank the whole tree.
+func (n *NamespaceModel) loadCountsCmd(includeQueues bool, topics []string) tea.Cmd {
+	client := n.client
+
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), defaultContextTimeout)
+		defer cancel()
+
+		counts := make(map[string]azure.MessageCounts)
+
+		if includeQueues {
+			queueCounts, err := client.ListQueueMessageCounts(ctx)
+			if err != nil {
+				log.Printf("failed to load queue counts: %v", err)
+			}
+			for name, c := range queueCounts {
+				counts[name] = c
+			}
+		}
+
+		for _, topic := range topics {
+			subCounts, err := client.ListSubscriptionMessageCounts(ctx, topic)
+			if err != nil {
+				log.Printf("failed to load subscription counts for %s: %v", topic, err)
+				continue
+			}
+			for name, c := range subCounts {
+				counts[name] = c
+			}
+		}
+
+		return CountsLoadedMsg{Counts: counts, client: client}
+	}
+}
+
+// countBadges renders the counts shown next to node, or "" when none are known.
+func (n *NamespaceModel) countBadges(node *TreeNode) string {
+	if node.EntityName == "" {
+		return ""
+	}
+	c, ok := n.counts[node.EntityName]
+	if !ok {
+		return ""
+	}
+
+	switch node.Type {
+	case NodeTypeMessages:
+		if strings.HasSuffix(node.ID, "-dlq") {
+			return dlqBadge(c.DeadLetter, true)
+		}
+		return styles.Subtle.Render(fmt.Sprintf("%d", c.Active))
+
+	case NodeTypeQueue, NodeTypeSubscription:
+		badges := []string{styles.Subtle.Render(fmt.Sprintf("%d", c.Active))}
+		if c.DeadLetter > 0 {
+			badges = append(badges, dlqBadge(c.DeadLetter, false))
+		}
+		if c.Scheduled > 0 {
+			badges = append(badges, styles.Subtle.Render(fmt.Sprintf("⏱%d", c.Scheduled)))
+		}
+		if transfer := c.Transfer + c.TransferDeadLetter; transfer > 0 {
+			badges = append(badges, styles.Subtle.Render(fmt.Sprintf("⇄%d", transfer)))
+		}
+		return strings.Join(badges, " ")
+	}
+
+	return ""
+}
+
+// dlqBadge highlights non-empty dead-letter queues.
+func dlqBadge(count int32, bare bool) string {
+	text := fmt.Sprintf("✗%d", count)
+	if bare {
+		text = fmt.Sprintf("%d", count)
+	}
+	if count > 0 {
+		return styles.Error.Render(text)
+	}
+	return styles.Subtle.Render(text)
+}
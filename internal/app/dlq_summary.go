@@ -0,0 +1,318 @@
+package app
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/MonsieurTib/service-bus-tui/internal/azure"
+	"github.com/MonsieurTib/service-bus-tui/internal/styles"
+	"github.com/charmbracelet/bubbles/spinner"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/muesli/reflow/truncate"
+	"github.com/muesli/reflow/wordwrap"
+)
+
+// dlqSummaryPageSize is larger than messagesPageSize, since only the metadata of the messages is kept.
+const dlqSummaryPageSize = 250
+
+// messageGroup identifies the dead-lettered messages sharing a reason, an error description and a subject.
+type messageGroup struct {
+	reason      string
+	description string
+	subject     string
+}
+
+func groupOf(msg azure.MessageInfo) messageGroup {
+	return messageGroup{
+		reason:      msg.DeadLetterReason,
+		description: msg.DeadLetterErrorDescription,
+		subject:     msg.Subject,
+	}
+}
+
+func (g messageGroup) matches(msg azure.MessageInfo) bool {
+	return groupOf(msg) == g
+}
+
+func (g messageGroup) String() string {
+	reason := g.reason
+	if reason == "" {
+		reason = "(no reason)"
+	}
+	if g.subject != "" {
+		return fmt.Sprintf("%s, subject %s", reason, g.subject)
+	}
+	return reason
+}
+
+type dlqGroupStats struct {
+	group     messageGroup
+	count     int
+	firstSeen time.Time // earliest enqueue time in the group
+	lastSeen  time.Time // latest enqueue time in the group
+}
+
+// DLQSummaryModel peeks through a dead-letter subqueue and groups its messages by dead-letter reason, error
+// description and subject, so the shape of the problem is visible before looking at single messages.
+type DLQSummaryModel struct {
+	client      *azure.ServiceBusClient
+	entityName  string
+	groups      map[messageGroup]*dlqGroupStats
+	sorted      []*dlqGroupStats // by count, largest first
+	scanned     int
+	next        int64 // sequence number the next page is peeked from
+	running     bool
+	stopped     bool
+	err         error
+	selectedIdx int
+	spinner     spinner.Model
+	width       int
+	height      int
+}
+
+// DLQSummaryRequestedMsg opens the summary of the dead-letter subqueue of EntityName.
+type DLQSummaryRequestedMsg struct {
+	EntityName string
+}
+
+type DLQSummaryClosedMsg struct{}
+
+// DLQGroupSelectedMsg shows the messages of Group in the dead-letter subqueue of EntityName.
+type DLQGroupSelectedMsg struct {
+	EntityName string
+	Group      messageGroup
+}
+
+type dlqSummaryPageMsg struct {
+	EntityName string
+	Messages   []azure.MessageInfo
+	Err        error
+}
+
+func NewDLQSummaryModel(client *azure.ServiceBusClient, entityName string) *DLQSummaryModel {
+	s := spinner.New()
+	s.Spinner = spinner.MiniDot
+
+	return &DLQSummaryModel{
+		client:     client,
+		entityName: entityName,
+		groups:     make(map[messageGroup]*dlqGroupStats),
+		running:    true,
+		spinner:    s,
+	}
+}
+
+func (m *DLQSummaryModel) Init() tea.Cmd {
+	return tea.Batch(m.spinner.Tick, m.peekCmd(0))
+}
+
+func (m *DLQSummaryModel) Update(msg tea.Msg) (*DLQSummaryModel, tea.Cmd) {
+	var spinnerCmd tea.Cmd
+	m.spinner, spinnerCmd = m.spinner.Update(msg)
+
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "esc":
+			if m.running {
+				// Keep the groups found so far.
+				m.running = false
+				m.stopped = true
+				return m, nil
+			}
+			return m, func() tea.Msg { return DLQSummaryClosedMsg{} }
+		case "up", "k":
+			if m.selectedIdx > 0 {
+				m.selectedIdx--
+			}
+		case "down", "j":
+			if m.selectedIdx < len(m.sorted)-1 {
+				m.selectedIdx++
+			}
+		case "enter":
+			if m.selectedIdx >= len(m.sorted) {
+				return m, nil
+			}
+			m.running = false
+			selected := DLQGroupSelectedMsg{EntityName: m.entityName, Group: m.sorted[m.selectedIdx].group}
+			return m, func() tea.Msg { return selected }
+		}
+		return m, nil
+
+	case dlqSummaryPageMsg:
+		if msg.EntityName != m.entityName || !m.running {
+			return m, nil
+		}
+		if msg.Err != nil {
+			m.running = false
+			m.err = msg.Err
+			return m, nil
+		}
+
+		m.addMessages(msg.Messages)
+		next, more := nextPeekPage(msg.Messages, m.next)
+		if !more {
+			m.running = false
+			return m, nil
+		}
+		m.next = next
+		return m, m.peekCmd(m.next)
+	}
+
+	if m.running {
+		return m, spinnerCmd
+	}
+	return m, nil
+}
+
+func (m *DLQSummaryModel) addMessages(messages []azure.MessageInfo) {
+	for _, msg := range messages {
+		g := groupOf(msg)
+		stats, ok := m.groups[g]
+		if !ok {
+			stats = &dlqGroupStats{group: g, firstSeen: msg.EnqueuedTime, lastSeen: msg.EnqueuedTime}
+			m.groups[g] = stats
+			m.sorted = append(m.sorted, stats)
+		}
+		stats.count++
+		if msg.EnqueuedTime.Before(stats.firstSeen) {
+			stats.firstSeen = msg.EnqueuedTime
+		}
+		if msg.EnqueuedTime.After(stats.lastSeen) {
+			stats.lastSeen = msg.EnqueuedTime
+		}
+	}
+
+	m.scanned += len(messages)
+
+	// Keep the selected group selected while the order changes.
+	var selected *dlqGroupStats
+	if m.selectedIdx < len(m.sorted) {
+		selected = m.sorted[m.selectedIdx]
+	}
+	slices.SortStableFunc(m.sorted, func(a, b *dlqGroupStats) int {
+		return cmp.Compare(b.count, a.count)
+	})
+	if selected != nil {
+		m.selectedIdx = slices.Index(m.sorted, selected)
+	}
+}
+
+func (m *DLQSummaryModel) SetSize(width, height int) {
+	m.width = width
+	m.height = height
+}
+
+func (m *DLQSummaryModel) View() string {
+	var s strings.Builder
+
+	s.WriteString(detailHeaderStyle.Render("Dead-letter summary of " + m.entityName))
+	s.WriteString("\n")
+	s.WriteString(detailSeparator)
+	s.WriteString("\n")
+
+	status := fmt.Sprintf("%d messages scanned, %d groups", m.scanned, len(m.sorted))
+	switch {
+	case m.running:
+		s.WriteString(m.spinner.View())
+		s.WriteString(" ")
+		s.WriteString(styles.Subtle.Render(status + "..."))
+	case m.err != nil:
+		s.WriteString(styles.Error.Render(wordwrap.String(fmt.Sprintf("%s, stopped by an error: %v", status, m.err), max(m.width, 10))))
+	case m.stopped:
+		s.WriteString(styles.Subtle.Render(status + " (stopped)"))
+	default:
+		s.WriteString(styles.Subtle.Render(status))
+	}
+	s.WriteString("\n\n")
+
+	if len(m.sorted) > 0 {
+		m.viewGroups(&s)
+	} else if !m.running {
+		s.WriteString(styles.Subtle.Render("No dead-lettered messages"))
+		s.WriteString("\n")
+	}
+
+	s.WriteString("\n")
+	if m.running {
+		s.WriteString(styles.Subtle.Render("↑↓: navigate • enter: show messages • esc: stop"))
+	} else {
+		s.WriteString(styles.Subtle.Render("↑↓: navigate • enter: show messages • esc: close"))
+	}
+	return s.String()
+}
+
+func (m *DLQSummaryModel) viewGroups(s *strings.Builder) {
+	const countWidth, timeWidth = 7, 19
+
+	// Reason, description and subject share what the count and the two timestamps leave.
+	available := max(m.width-countWidth-2*timeWidth-5*2, 30)
+	reasonWidth := available * 3 / 10
+	subjectWidth := available / 4
+	descriptionWidth := available - reasonWidth - subjectWidth
+
+	cell := func(value string, width int) string {
+		value = truncate.StringWithTail(value, uint(width), "…")
+		return value + strings.Repeat(" ", max(width-len([]rune(value)), 0))
+	}
+	row := func(count, reason, description, subject, first, last string) string {
+		return strings.Join([]string{
+			fmt.Sprintf("%*s", countWidth, count),
+			cell(reason, reasonWidth),
+			cell(description, descriptionWidth),
+			cell(subject, subjectWidth),
+			cell(first, timeWidth),
+			cell(last, timeWidth),
+		}, "  ")
+	}
+
+	s.WriteString(detailLabelStyle.Render(row("Count", "Reason", "Description", "Subject", "First seen", "Last seen")))
+	s.WriteString("\n")
+
+	// Reserve: title and separator (2) + status and blank (2) + header (1) + blank and footer (2)
+	visible := max(m.height-7, 3)
+	start := max(m.selectedIdx-visible+1, 0)
+	end := min(start+visible, len(m.sorted))
+
+	for i := start; i < end; i++ {
+		g := m.sorted[i]
+		line := row(
+			fmt.Sprintf("%d", g.count),
+			valueOrDash(g.group.reason),
+			valueOrDash(g.group.description),
+			valueOrDash(g.group.subject),
+			formatTime(g.firstSeen),
+			formatTime(g.lastSeen),
+		)
+		if i == m.selectedIdx {
+			s.WriteString(styles.Selected.Render(line))
+		} else {
+			s.WriteString(line)
+		}
+		s.WriteString("\n")
+	}
+}
+
+func valueOrDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+func (m *DLQSummaryModel) peekCmd(fromSequenceNumber int64) tea.Cmd {
+	client := m.client
+	entityName := m.entityName
+
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), defaultContextTimeout)
+		defer cancel()
+
+		messages, err := client.PeekMessages(ctx, entityName, true, fromSequenceNumber, dlqSummaryPageSize)
+		return dlqSummaryPageMsg{EntityName: entityName, Messages: messages, Err: err}
+	}
+}
@@ -0,0 +1,224 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/MonsieurTib/service-bus-tui/internal/azure"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const editorTemplateHeader = `# Edit the message below, then save and quit to send it.
+# Headers end at the first blank line, everything after it is the body. Values with
+# line breaks are quoted, e.g. Subject: "first\nsecond".
+# Properties are written as key=value; other=42. Quoted values ("00123") are strings,
+# other types are spelled out, e.g. count:int32=5. Clear the file to cancel.
+`
+
+// MessageEditedMsg carries the message saved in $EDITOR.
+type MessageEditedMsg struct {
+	Message   azure.MessageInfo
+	Cancelled bool
+	Err       error
+}
+
+// editMessageCmd writes msg to a temp file, suspends the program while $EDITOR runs on it,
+// and parses the saved file back into a message.
+func editMessageCmd(msg azure.MessageInfo) tea.Cmd {
+	path, err := writeMessageTemplate(msg)
+	if err != nil {
+		return func() tea.Msg { return MessageEditedMsg{Err: err} }
+	}
+
+	return tea.ExecProcess(editorCommand(path), func(err error) tea.Msg {
+		defer os.Remove(path)
+
+		if err != nil {
+			return MessageEditedMsg{Err: fmt.Errorf("editor exited with an error: %w", err)}
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return MessageEditedMsg{Err: fmt.Errorf("failed to read edited message: %w", err)}
+		}
+
+		edited, ok, err := parseMessageTemplate(string(content))
+		if err != nil {
+			return MessageEditedMsg{Err: err}
+		}
+		return MessageEditedMsg{Message: edited, Cancelled: !ok}
+	})
+}
+
+func writeMessageTemplate(msg azure.MessageInfo) (string, error) {
+	f, err := os.CreateTemp("", "service-bus-tui-*.txt")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temp file: %w", err)
+	}
+
+	_, err = f.WriteString(messageTemplate(msg))
+	if err = errors.Join(err, f.Close()); err != nil {
+		os.Remove(f.Name())
+		return "", fmt.Errorf("failed to write temp file: %w", err)
+	}
+	return f.Name(), nil
+}
+
+// editorCommand opens path in $VISUAL or $EDITOR, falling back to vi. The variable may include arguments, e.g. "code -w".
+func editorCommand(path string) *exec.Cmd {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+
+	args := strings.Fields(editor)
+	if len(args) == 0 {
+		args = []string{"vi"}
+	}
+	return exec.Command(args[0], append(args[1:], path)...)
+}
+
+func messageTemplate(msg azure.MessageInfo) string {
+	var b strings.Builder
+
+	b.WriteString(editorTemplateHeader)
+	writeHeader(&b, "Content-Type", msg.ContentType)
+	writeHeader(&b, "Subject", msg.Subject)
+	writeHeader(&b, "Message-ID", msg.MessageID)
+	writeHeader(&b, "Correlation-ID", msg.CorrelationID)
+	writeHeader(&b, "Session-ID", msg.SessionID)
+	writeHeader(&b, "Reply-To", msg.ReplyTo)
+	writeHeader(&b, "Reply-To-Session-ID", msg.ReplyToSessionID)
+	writeHeader(&b, "To", msg.To)
+	writeHeader(&b, "Partition-Key", msg.PartitionKey)
+
+	ttl := ""
+	if msg.TimeToLive > 0 {
+		ttl = msg.TimeToLive.String()
+	}
+	writeHeader(&b, "TTL", ttl)
+
+	scheduled := ""
+	if !msg.ScheduledEnqueueTime.IsZero() {
+		scheduled = msg.ScheduledEnqueueTime.Local().Format(scheduledTimeLayout)
+	}
+	writeHeader(&b, "Scheduled", scheduled)
+	// Properties quote their own strings, see formatProperties.
+	b.WriteString("Properties: " + formatProperties(msg.Properties) + "\n")
+
+	b.WriteString("\n")
+	b.WriteString(msg.Body)
+	b.WriteString("\n")
+
+	return b.String()
+}
+
+// writeHeader writes a header line. Values that would not read back as written, such as ones with line breaks or
+// surrounding spaces, are quoted.
+func writeHeader(b *strings.Builder, name, value string) {
+	if value != strings.TrimSpace(value) || strings.HasPrefix(value, `"`) || strings.ContainsFunc(value, unicode.IsControl) {
+		value = strconv.Quote(value)
+	}
+	b.WriteString(name)
+	b.WriteString(": ")
+	b.WriteString(value)
+	b.WriteString("\n")
+}
+
+// parseMessageTemplate parses a file written by messageTemplate. ok is false when the file was cleared.
+func parseMessageTemplate(content string) (msg azure.MessageInfo, ok bool, err error) {
+	lines := strings.Split(content, "\n")
+
+	i := 0
+	for ; i < len(lines); i++ {
+		line := strings.TrimRight(lines[i], "\r")
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+		if strings.TrimSpace(line) == "" {
+			break
+		}
+
+		ok = true
+		name, value, found := strings.Cut(line, ":")
+		if !found {
+			return msg, false, fmt.Errorf("invalid header %q, expected Name: value", line)
+		}
+		if err := setHeader(&msg, strings.TrimSpace(name), strings.TrimSpace(value)); err != nil {
+			return msg, false, err
+		}
+	}
+
+	if i+1 < len(lines) {
+		msg.Body = strings.TrimSuffix(strings.Join(lines[i+1:], "\n"), "\n")
+		if strings.TrimSpace(msg.Body) != "" {
+			ok = true
+		}
+	}
+
+	return msg, ok, nil
+}
+
+func setHeader(msg *azure.MessageInfo, name, value string) error {
+	if strings.HasPrefix(value, `"`) && !strings.EqualFold(name, "properties") {
+		unquoted, err := strconv.Unquote(value)
+		if err != nil {
+			return fmt.Errorf("invalid quoted value of %s: %s", name, value)
+		}
+		value = unquoted
+	}
+
+	switch strings.ToLower(name) {
+	case "content-type":
+		msg.ContentType = value
+	case "subject":
+		msg.Subject = value
+	case "message-id":
+		msg.MessageID = value
+	case "correlation-id":
+		msg.CorrelationID = value
+	case "session-id":
+		msg.SessionID = value
+	case "reply-to":
+		msg.ReplyTo = value
+	case "reply-to-session-id":
+		msg.ReplyToSessionID = value
+	case "to":
+		msg.To = value
+	case "partition-key":
+		msg.PartitionKey = value
+	case "ttl":
+		if value == "" {
+			return nil
+		}
+		d, err := time.ParseDuration(value)
+		if err != nil || d <= 0 {
+			return fmt.Errorf("invalid TTL: %q", value)
+		}
+		msg.TimeToLive = d
+	case "scheduled":
+		if value == "" {
+			return nil
+		}
+		t, err := parseScheduledTime(value)
+		if err != nil {
+			return err
+		}
+		msg.ScheduledEnqueueTime = t
+	case "properties":
+		props, err := parseProperties(value)
+		if err != nil {
+			return err
+		}
+		msg.Properties = props
+	default:
+		return fmt.Errorf("unknown header %q", name)
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package app
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/MonsieurTib/service-bus-tui/internal/azure"
+)
+
+func TestMessageTemplateRoundTrip(t *testing.T) {
+	tests := []azure.MessageInfo{
+		{Body: "hello"},
+		{
+			Body:             "{\n  \"id\": 1\n}",
+			ContentType:      "application/json",
+			Subject:          "order.created",
+			MessageID:        "msg-1",
+			CorrelationID:    "corr-1",
+			SessionID:        "session-1",
+			ReplyTo:          "replies",
+			ReplyToSessionID: "reply-session",
+			To:               "orders",
+			PartitionKey:     "pk",
+			TimeToLive:       90 * time.Second,
+			Properties:       map[string]any{"count": int32(5), "zip": "00123", "note": "a\nb"},
+		},
+		{
+			Body:          "body",
+			Subject:       "first line\nsecond line",
+			MessageID:     "id\r\n",
+			CorrelationID: `"quoted"`,
+			SessionID:     "  padded  ",
+			ReplyTo:       "tab\there",
+		},
+	}
+
+	for _, want := range tests {
+		content := messageTemplate(want)
+		got, ok, err := parseMessageTemplate(content)
+		if err != nil {
+			t.Errorf("parseMessageTemplate(%q): %v", content, err)
+			continue
+		}
+		if !ok {
+			t.Errorf("parseMessageTemplate(%q) reported a cleared file", content)
+			continue
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("round trip through %q gave %#v, want %#v", content, got, want)
+		}
+	}
+}
+
+func TestParseMessageTemplateInvalidQuote(t *testing.T) {
+	if _, _, err := parseMessageTemplate("Subject: \"unterminated\n\nbody\n"); err == nil {
+		t.Error("expected an error for an unterminated quoted header value")
+	}
+}
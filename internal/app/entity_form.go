@@ -0,0 +1,483 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/MonsieurTib/service-bus-tui/internal/azure"
+	"github.com/MonsieurTib/service-bus-tui/internal/styles"
+	"github.com/charmbracelet/bubbles/spinner"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/muesli/reflow/wordwrap"
+)
+
+const (
+	entityFieldName = iota
+	entityFieldMaxSize
+	entityFieldDefaultTTL
+	entityFieldLockDuration
+	entityFieldMaxDelivery
+	entityFieldDuplicateDetection
+	entityFieldSessions
+	entityFieldPartitioning
+	entityFieldForwardTo
+	entityFieldForwardDLQTo
+	entityFieldStatus
+)
+
+var entityFieldLabels = map[int]string{
+	entityFieldName:               "Name",
+	entityFieldMaxSize:            "Max size (MB)",
+	entityFieldDefaultTTL:         "Default TTL",
+	entityFieldLockDuration:       "Lock duration",
+	entityFieldMaxDelivery:        "Max delivery",
+	entityFieldDuplicateDetection: "Duplicate det.",
+	entityFieldSessions:           "Sessions",
+	entityFieldPartitioning:       "Partitioning",
+	entityFieldForwardTo:          "Forward to",
+	entityFieldForwardDLQTo:       "Forward DLQ to",
+	entityFieldStatus:             "Status",
+}
+
+var entityFieldPlaceholders = map[int]string{
+	entityFieldMaxSize:            "1024",
+	entityFieldDefaultTTL:         "e.g. 14d, 1h30m, never",
+	entityFieldLockDuration:       "e.g. 1m",
+	entityFieldMaxDelivery:        "10",
+	entityFieldDuplicateDetection: "window, e.g. 10m (blank: disabled)",
+	entityFieldSessions:           "yes/no",
+	entityFieldPartitioning:       "yes/no",
+	entityFieldForwardTo:          "queue or topic (blank: no forwarding)",
+	entityFieldForwardDLQTo:       "queue or topic (blank: no forwarding)",
+	entityFieldStatus:             "Active, Disabled, SendDisabled, ReceiveDisabled",
+}
+
+// editEntityFieldPlaceholders replace entityFieldPlaceholders when editing, where blank fields keep the current
+// value. Duplicate detection can't be turned on or off after create, only its window changed.
+var editEntityFieldPlaceholders = map[int]string{
+	entityFieldDuplicateDetection: "window, e.g. 10m (blank: keep)",
+}
+
+// entityFields lists the form fields of each entity type. Sessions and partitioning can only be set on create.
+var entityFields = map[string][]int{
+	NodeTypeQueue: {
+		entityFieldName, entityFieldMaxSize, entityFieldDefaultTTL, entityFieldLockDuration, entityFieldMaxDelivery,
+		entityFieldDuplicateDetection, entityFieldSessions, entityFieldPartitioning, entityFieldForwardTo,
+		entityFieldForwardDLQTo, entityFieldStatus,
+	},
+	NodeTypeTopic: {
+		entityFieldName, entityFieldMaxSize, entityFieldDefaultTTL, entityFieldDuplicateDetection,
+		entityFieldPartitioning, entityFieldStatus,
+	},
+	NodeTypeSubscription: {
+		entityFieldName, entityFieldDefaultTTL, entityFieldLockDuration, entityFieldMaxDelivery, entityFieldSessions,
+		entityFieldForwardTo, entityFieldForwardDLQTo, entityFieldStatus,
+	},
+}
+
+var createOnlyEntityFields = []int{entityFieldName, entityFieldSessions, entityFieldPartitioning}
+
+var entityStatuses = []string{"Active", "Disabled", "SendDisabled", "ReceiveDisabled"}
+
+// EntityFormModel creates or edits a queue, topic or subscription.
+type EntityFormModel struct {
+	client     *azure.ServiceBusClient
+	nodeType   string
+	isCreate   bool
+	entityName string // the entity being edited, or the topic of a new subscription
+	fields     []int
+	inputs     []textinput.Model
+	focusIdx   int
+	spinner    spinner.Model
+	isLoading  bool
+	isSaving   bool
+	errMsg     string
+	width      int
+	height     int
+}
+
+// EntityFormRequestedMsg opens the entity form. For a new subscription EntityName is the topic.
+type EntityFormRequestedMsg struct {
+	NodeType   string
+	EntityName string
+	Create     bool
+}
+
+type EntityFormClosedMsg struct{}
+
+type entityFormLoadedMsg struct {
+	Properties *azure.EntityProperties
+	Err        error
+}
+
+// EntitySavedMsg is sent once an entity has been created, updated or deleted.
+type EntitySavedMsg struct {
+	EntityName string
+	Err        error
+}
+
+func NewEntityFormModel(client *azure.ServiceBusClient, req EntityFormRequestedMsg) *EntityFormModel {
+	s := spinner.New()
+	s.Spinner = spinner.MiniDot
+
+	m := &EntityFormModel{
+		client:     client,
+		nodeType:   req.NodeType,
+		isCreate:   req.Create,
+		entityName: req.EntityName,
+		spinner:    s,
+	}
+
+	for _, field := range entityFields[req.NodeType] {
+		if !req.Create && slices.Contains(createOnlyEntityFields, field) {
+			continue
+		}
+		ti := textinput.New()
+		ti.Prompt = ""
+		ti.Placeholder = entityFieldPlaceholders[field]
+		if placeholder, ok := editEntityFieldPlaceholders[field]; ok && !req.Create {
+			ti.Placeholder = placeholder
+		}
+		m.fields = append(m.fields, field)
+		m.inputs = append(m.inputs, ti)
+	}
+	if len(m.inputs) > 0 {
+		m.inputs[0].Focus()
+	}
+
+	return m
+}
+
+func (m *EntityFormModel) Init() tea.Cmd {
+	if m.isCreate {
+		return textinput.Blink
+	}
+	m.isLoading = true
+	return tea.Batch(m.spinner.Tick, m.loadCmd())
+}
+
+func (m *EntityFormModel) Update(msg tea.Msg) (*EntityFormModel, tea.Cmd) {
+	var spinnerCmd tea.Cmd
+	m.spinner, spinnerCmd = m.spinner.Update(msg)
+
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		if msg.String() == "esc" {
+			return m, func() tea.Msg { return EntityFormClosedMsg{} }
+		}
+		if m.isLoading || m.isSaving {
+			return m, nil
+		}
+
+		switch msg.String() {
+		case "tab", "down", "enter":
+			return m, m.focusField(m.focusIdx + 1)
+		case "shift+tab", "up":
+			return m, m.focusField(m.focusIdx - 1)
+		case "ctrl+s":
+			return m, m.save()
+		}
+
+		var cmd tea.Cmd
+		m.inputs[m.focusIdx], cmd = m.inputs[m.focusIdx].Update(msg)
+		return m, cmd
+
+	case entityFormLoadedMsg:
+		m.isLoading = false
+		if msg.Err != nil {
+			m.errMsg = msg.Err.Error()
+		} else {
+			m.prefill(msg.Properties)
+		}
+
+	case EntitySavedMsg:
+		m.isSaving = false
+		if msg.Err != nil {
+			m.errMsg = msg.Err.Error()
+		}
+	}
+
+	if m.isLoading || m.isSaving {
+		return m, spinnerCmd
+	}
+
+	var cmd tea.Cmd
+	m.inputs[m.focusIdx], cmd = m.inputs[m.focusIdx].Update(msg)
+	return m, cmd
+}
+
+func (m *EntityFormModel) focusField(idx int) tea.Cmd {
+	m.inputs[m.focusIdx].Blur()
+	m.focusIdx = (idx + len(m.inputs)) % len(m.inputs)
+	return m.inputs[m.focusIdx].Focus()
+}
+
+func (m *EntityFormModel) SetSize(width, height int) {
+	m.width = width
+	m.height = height
+
+	for i := range m.inputs {
+		m.inputs[i].Width = max(width-17, 10)
+	}
+}
+
+func (m *EntityFormModel) setValue(field int, value string) {
+	if i := slices.Index(m.fields, field); i >= 0 {
+		m.inputs[i].SetValue(value)
+	}
+}
+
+func (m *EntityFormModel) value(field int) string {
+	if i := slices.Index(m.fields, field); i >= 0 {
+		return strings.TrimSpace(m.inputs[i].Value())
+	}
+	return ""
+}
+
+func (m *EntityFormModel) prefill(props *azure.EntityProperties) {
+	if props.MaxSizeInMegabytes != nil {
+		m.setValue(entityFieldMaxSize, fmt.Sprintf("%d", *props.MaxSizeInMegabytes))
+	}
+	m.setValue(entityFieldDefaultTTL, formatISODuration(props.DefaultMessageTimeToLive))
+	m.setValue(entityFieldLockDuration, formatISODuration(props.LockDuration))
+	if props.MaxDeliveryCount != nil {
+		m.setValue(entityFieldMaxDelivery, fmt.Sprintf("%d", *props.MaxDeliveryCount))
+	}
+	if props.RequiresDuplicateDetection != nil && *props.RequiresDuplicateDetection {
+		m.setValue(entityFieldDuplicateDetection, formatISODuration(props.DuplicateDetectionHistoryTimeWindow))
+	}
+	m.setValue(entityFieldForwardTo, stringValue(props.ForwardTo))
+	m.setValue(entityFieldForwardDLQTo, stringValue(props.ForwardDeadLetteredMessagesTo))
+	m.setValue(entityFieldStatus, props.Status)
+}
+
+// buildProperties validates the fields. Blank fields are left nil so the service default, or the
+// current value when editing, is kept, except forwarding: clearing it when editing turns it off.
+func (m *EntityFormModel) buildProperties() (string, azure.EntityProperties, error) {
+	var props azure.EntityProperties
+
+	name := m.entityName
+	if m.isCreate {
+		name = m.value(entityFieldName)
+		if name == "" {
+			return "", props, fmt.Errorf("name cannot be empty")
+		}
+		if m.nodeType == NodeTypeSubscription {
+			name = m.entityName + "/" + name
+		}
+	}
+
+	var err error
+	if props.MaxSizeInMegabytes, err = parseInt32Field(m.value(entityFieldMaxSize), "max size"); err != nil {
+		return "", props, err
+	}
+	if props.MaxDeliveryCount, err = parseInt32Field(m.value(entityFieldMaxDelivery), "max delivery count"); err != nil {
+		return "", props, err
+	}
+	if props.DefaultMessageTimeToLive, err = parseDurationField(m.value(entityFieldDefaultTTL), "default TTL"); err != nil {
+		return "", props, err
+	}
+	if props.LockDuration, err = parseDurationField(m.value(entityFieldLockDuration), "lock duration"); err != nil {
+		return "", props, err
+	}
+	if props.DuplicateDetectionHistoryTimeWindow, err = parseDurationField(m.value(entityFieldDuplicateDetection), "duplicate detection window"); err != nil {
+		return "", props, err
+	}
+	if m.isCreate && props.DuplicateDetectionHistoryTimeWindow != nil {
+		enabled := true
+		props.RequiresDuplicateDetection = &enabled
+	}
+	if props.RequiresSession, err = parseBoolField(m.value(entityFieldSessions), "sessions"); err != nil {
+		return "", props, err
+	}
+	if props.EnablePartitioning, err = parseBoolField(m.value(entityFieldPartitioning), "partitioning"); err != nil {
+		return "", props, err
+	}
+
+	// The forwarding fields are prefilled when editing, so a blank one was cleared rather than left untouched.
+	if forwardTo := m.value(entityFieldForwardTo); forwardTo != "" || !m.isCreate {
+		props.ForwardTo = &forwardTo
+	}
+	if forwardDLQTo := m.value(entityFieldForwardDLQTo); forwardDLQTo != "" || !m.isCreate {
+		props.ForwardDeadLetteredMessagesTo = &forwardDLQTo
+	}
+
+	if status := m.value(entityFieldStatus); status != "" {
+		i := slices.IndexFunc(entityStatuses, func(s string) bool { return strings.EqualFold(s, status) })
+		if i < 0 {
+			return "", props, fmt.Errorf("invalid status %q, expected one of %s", status, strings.Join(entityStatuses, ", "))
+		}
+		props.Status = entityStatuses[i]
+	}
+
+	return name, props, nil
+}
+
+func parseInt32Field(s, label string) (*int32, error) {
+	if s == "" {
+		return nil, nil
+	}
+	v, err := strconv.ParseInt(s, 10, 32)
+	if err != nil || v <= 0 {
+		return nil, fmt.Errorf("invalid %s: %q", label, s)
+	}
+	n := int32(v)
+	return &n, nil
+}
+
+// parseDurationField accepts Go durations, whole days such as "14d", and "never", and returns an ISO 8601 duration.
+func parseDurationField(s, label string) (*string, error) {
+	if s == "" {
+		return nil, nil
+	}
+	if strings.EqualFold(s, "never") {
+		never := azure.NeverExpires
+		return &never, nil
+	}
+
+	var d time.Duration
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n <= 0 {
+			return nil, fmt.Errorf("invalid %s: %q", label, s)
+		}
+		d = time.Duration(n) * 24 * time.Hour
+	} else {
+		var err error
+		if d, err = time.ParseDuration(s); err != nil || d <= 0 {
+			return nil, fmt.Errorf("invalid %s: %q", label, s)
+		}
+	}
+
+	iso := azure.FormatISODuration(d)
+	return &iso, nil
+}
+
+func parseBoolField(s, label string) (*bool, error) {
+	var b bool
+	switch strings.ToLower(s) {
+	case "":
+		return nil, nil
+	case "y", "yes", "true":
+		b = true
+	case "n", "no", "false":
+		b = false
+	default:
+		return nil, fmt.Errorf("invalid %s: %q, expected yes or no", label, s)
+	}
+	return &b, nil
+}
+
+func (m *EntityFormModel) save() tea.Cmd {
+	name, props, err := m.buildProperties()
+	if err != nil {
+		m.errMsg = err.Error()
+		return nil
+	}
+
+	m.errMsg = ""
+	m.isSaving = true
+	return tea.Batch(m.spinner.Tick, m.saveCmd(name, props))
+}
+
+func (m *EntityFormModel) title() string {
+	switch {
+	case m.isCreate && m.nodeType == NodeTypeSubscription:
+		return "New subscription in " + m.entityName
+	case m.isCreate:
+		return "New " + m.nodeType
+	}
+	return fmt.Sprintf("Edit %s %s", m.nodeType, m.entityName)
+}
+
+func (m *EntityFormModel) View() string {
+	var s strings.Builder
+
+	s.WriteString(detailHeaderStyle.Render(m.title()))
+	s.WriteString("\n")
+	s.WriteString(detailSeparator)
+	s.WriteString("\n\n")
+
+	if m.isLoading {
+		s.WriteString(m.spinner.View())
+		s.WriteString(" ")
+		s.WriteString(styles.Subtle.Render("Loading properties..."))
+		return s.String()
+	}
+
+	for i, input := range m.inputs {
+		label := fmt.Sprintf("%-15s", entityFieldLabels[m.fields[i]]+":")
+		if i == m.focusIdx {
+			s.WriteString(styles.Label.Render(label))
+		} else {
+			s.WriteString(detailLabelStyle.Render(label))
+		}
+		s.WriteString(" ")
+		s.WriteString(input.View())
+		s.WriteString("\n")
+	}
+
+	s.WriteString("\n")
+	switch {
+	case m.isSaving:
+		s.WriteString(m.spinner.View())
+		s.WriteString(" ")
+		s.WriteString(styles.Subtle.Render("Saving..."))
+	case m.errMsg != "":
+		s.WriteString(styles.Error.Render(wordwrap.String(m.errMsg, max(m.width, 10))))
+	default:
+		s.WriteString(styles.Subtle.Render("Blank fields keep the default or current value"))
+	}
+	s.WriteString("\n\n")
+	s.WriteString(styles.Subtle.Render("tab/↑↓: next field • ctrl+s: save • esc: cancel"))
+
+	return s.String()
+}
+
+func (m *EntityFormModel) loadCmd() tea.Cmd {
+	client := m.client
+	nodeType := m.nodeType
+	entityName := m.entityName
+
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), defaultContextTimeout)
+		defer cancel()
+
+		props, err := getEntityProperties(ctx, client, nodeType, entityName)
+		return entityFormLoadedMsg{Properties: props, Err: err}
+	}
+}
+
+func (m *EntityFormModel) saveCmd(entityName string, props azure.EntityProperties) tea.Cmd {
+	client := m.client
+	nodeType := m.nodeType
+	isCreate := m.isCreate
+
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), defaultContextTimeout)
+		defer cancel()
+
+		var err error
+		switch {
+		case nodeType == NodeTypeQueue && isCreate:
+			err = client.CreateQueue(ctx, entityName, props)
+		case nodeType == NodeTypeQueue:
+			err = client.UpdateQueue(ctx, entityName, props)
+		case nodeType == NodeTypeTopic && isCreate:
+			err = client.CreateTopic(ctx, entityName, props)
+		case nodeType == NodeTypeTopic:
+			err = client.UpdateTopic(ctx, entityName, props)
+		case nodeType == NodeTypeSubscription && isCreate:
+			err = client.CreateSubscription(ctx, entityName, props)
+		case nodeType == NodeTypeSubscription:
+			err = client.UpdateSubscription(ctx, entityName, props)
+		}
+
+		return EntitySavedMsg{EntityName: entityName, Err: err}
+	}
+}
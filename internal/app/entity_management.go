@@ -0,0 +1,297 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/MonsieurTib/service-bus-tui/internal/azure"
+	"github.com/MonsieurTib/service-bus-tui/internal/styles"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/muesli/reflow/wordwrap"
+)
+
+// deleteState tracks the deletion of a topic, queue or subscription, confirmed by typing its name.
+type deleteState struct {
+	node    *TreeNode
+	input   textinput.Model
+	running bool
+	err     error
+}
+
+// treeState is restored once the tree has been reloaded by refresh.
+type treeState struct {
+	expandedIDs  map[string]bool
+	selectedID   string
+	pendingLoads int // subscription and rule loads started by the restore
+}
+
+type EntityDeletedMsg struct {
+	EntityName string
+	Err        error
+}
+
+// isPrompting reports whether the tree is waiting for input, so keys such as tab are not handled by the explorer.
+func (n *NamespaceModel) isPrompting() bool {
+	return n.pendingNew || n.deleting != nil || n.ruleDraft != nil
+}
+
+// updatePendingNew picks the type of entity to create after "n".
+func (n *NamespaceModel) updatePendingNew(msg tea.KeyMsg) tea.Cmd {
+	n.pendingNew = false
+
+	var req EntityFormRequestedMsg
+	switch msg.String() {
+	case "q":
+		req = EntityFormRequestedMsg{NodeType: NodeTypeQueue, Create: true}
+	case "t":
+		req = EntityFormRequestedMsg{NodeType: NodeTypeTopic, Create: true}
+	case "s":
+		topic, ok := n.selectedTopic()
+		if !ok {
+			return nil
+		}
+		req = EntityFormRequestedMsg{NodeType: NodeTypeSubscription, EntityName: topic, Create: true}
+	case "r":
+		sub, ok := n.selectedSubscription()
+		if !ok {
+			return nil
+		}
+		return func() tea.Msg { return RuleFormRequestedMsg{EntityName: sub} }
+	default:
+		return nil
+	}
+
+	return func() tea.Msg { return req }
+}
+
+// selectedTopic returns the topic of the selected topic, subscription or subscription messages node.
+func (n *NamespaceModel) selectedTopic() (string, bool) {
+	node := n.selectedNode()
+	if node == nil {
+		return "", false
+	}
+	if node.Type == NodeTypeTopic {
+		return node.EntityName, true
+	}
+	topic, _, isSubscription := strings.Cut(node.EntityName, "/")
+	return topic, isSubscription
+}
+
+func isManagedEntity(node *TreeNode) bool {
+	if node == nil {
+		return false
+	}
+	switch node.Type {
+	case NodeTypeTopic, NodeTypeQueue, NodeTypeSubscription, NodeTypeRule:
+		return true
+	}
+	return false
+}
+
+func (n *NamespaceModel) editSelectedEntity() tea.Cmd {
+	node := n.selectedNode()
+	if !isManagedEntity(node) {
+		return nil
+	}
+
+	if node.Type == NodeTypeRule {
+		req := RuleFormRequestedMsg{EntityName: node.EntityName, Rule: node.Rule}
+		return func() tea.Msg { return req }
+	}
+
+	req := EntityFormRequestedMsg{NodeType: node.Type, EntityName: node.EntityName}
+	return func() tea.Msg { return req }
+}
+
+func (n *NamespaceModel) startDelete() tea.Cmd {
+	node := n.selectedNode()
+	if !isManagedEntity(node) {
+		return nil
+	}
+
+	ti := textinput.New()
+	ti.Prompt = "> "
+	ti.Placeholder = node.Name
+	ti.Width = max(n.viewport.Width-4, 10)
+
+	n.deleting = &deleteState{node: node, input: ti}
+	return n.deleting.input.Focus()
+}
+
+func (n *NamespaceModel) updateDelete(msg tea.KeyMsg) tea.Cmd {
+	d := n.deleting
+	if d.running {
+		return nil
+	}
+
+	switch msg.String() {
+	case "esc":
+		n.deleting = nil
+		return nil
+	case "enter":
+		if strings.TrimSpace(d.input.Value()) != d.node.Name {
+			d.err = fmt.Errorf("type %q to confirm", d.node.Name)
+			return nil
+		}
+		d.err = nil
+		d.running = true
+		return tea.Batch(n.spinner.Tick, n.deleteEntityCmd(d.node))
+	}
+
+	var cmd tea.Cmd
+	d.input, cmd = d.input.Update(msg)
+	return cmd
+}
+
+func (n *NamespaceModel) viewDelete() string {
+	d := n.deleting
+	width := max(n.viewport.Width-2, 10)
+
+	var s strings.Builder
+
+	question := fmt.Sprintf("Delete %s %s and all its messages?", d.node.Type, d.node.EntityName)
+	if d.node.Type == NodeTypeRule {
+		question = fmt.Sprintf("Delete rule %s from %s?", d.node.Name, d.node.EntityName)
+	}
+	s.WriteString(styles.Error.Render(wordwrap.String(question, width)))
+	s.WriteString("\n\n")
+
+	if d.running {
+		s.WriteString(n.spinner.View())
+		s.WriteString(" ")
+		s.WriteString(styles.Subtle.Render("Deleting..."))
+		return s.String()
+	}
+
+	s.WriteString(wordwrap.String("Type the name to confirm:", width))
+	s.WriteString("\n")
+	s.WriteString(d.input.View())
+	s.WriteString("\n\n")
+	if d.err != nil {
+		s.WriteString(styles.Error.Render(wordwrap.String(d.err.Error(), width)))
+		s.WriteString("\n\n")
+	}
+	s.WriteString(styles.Subtle.Render("enter: delete • esc: cancel"))
+
+	return s.String()
+}
+
+func (n *NamespaceModel) viewPendingNew() string {
+	var s strings.Builder
+	s.WriteString(detailHeaderStyle.Render("New entity"))
+	s.WriteString("\n\n")
+	s.WriteString("q: queue\n")
+	s.WriteString("t: topic\n")
+	if topic, ok := n.selectedTopic(); ok {
+		s.WriteString(wordwrap.String(fmt.Sprintf("s: subscription in %s", topic), max(n.viewport.Width-2, 10)))
+		s.WriteString("\n")
+	}
+	if sub, ok := n.selectedSubscription(); ok {
+		s.WriteString(wordwrap.String(fmt.Sprintf("r: rule in %s", sub), max(n.viewport.Width-2, 10)))
+		s.WriteString("\n")
+	}
+	s.WriteString("\n")
+	s.WriteString(styles.Subtle.Render("esc: cancel"))
+	return s.String()
+}
+
+// refresh reloads the tree after entities were created, updated or deleted, keeping expanded nodes and the
+// selection where they still exist.
+func (n *NamespaceModel) refresh() tea.Cmd {
+	state := &treeState{expandedIDs: make(map[string]bool)}
+	for _, node := range n.flatList {
+		if node.IsExpanded {
+			state.expandedIDs[node.ID] = true
+		}
+	}
+	if node := n.selectedNode(); node != nil {
+		state.selectedID = node.ID
+	}
+	n.restore = state
+
+	n.cacheMutex.Lock()
+	n.subscriptionCache = make(map[string][]*TreeNode)
+	n.cacheMutex.Unlock()
+
+	n.counts = make(map[string]azure.MessageCounts)
+	n.errMsg = ""
+	n.isLoading = true
+
+	return tea.Batch(n.spinner.Tick, n.loadTopicsAndQueuesCmd())
+}
+
+// restoreTreeState expands the nodes that were expanded before refresh and moves the cursor back to the node
+// that was selected. It runs for the root nodes, with an empty parentID, and again for the children of each
+// re-expanded topic or "Rules" node once they are loaded.
+func (n *NamespaceModel) restoreTreeState(nodes []*TreeNode, parentID string) tea.Cmd {
+	r := n.restore
+	if r == nil {
+		return nil
+	}
+	if parentID != "" {
+		if !r.expandedIDs[parentID] {
+			return nil
+		}
+		delete(r.expandedIDs, parentID)
+		r.pendingLoads--
+	}
+
+	var cmds []tea.Cmd
+	expand := func(node *TreeNode) {
+		if r.expandedIDs[node.ID] {
+			if cmd := n.handleExpandNode(node); cmd != nil {
+				r.pendingLoads++
+				cmds = append(cmds, cmd)
+			}
+		}
+	}
+	for _, node := range nodes {
+		expand(node)
+		for _, child := range node.Children {
+			expand(child)
+		}
+	}
+	n.rebuildFlatList()
+
+	for i, node := range n.flatList {
+		if node.ID == r.selectedID {
+			n.selectedIdx = i
+			break
+		}
+	}
+	n.ensureSelectedVisible()
+
+	if r.pendingLoads <= 0 {
+		n.restore = nil
+	}
+
+	return tea.Batch(cmds...)
+}
+
+func (n *NamespaceModel) deleteEntityCmd(node *TreeNode) tea.Cmd {
+	client := n.client
+	entityName := node.EntityName
+	nodeType := node.Type
+	name := node.Name
+
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), defaultContextTimeout)
+		defer cancel()
+
+		var err error
+		switch nodeType {
+		case NodeTypeRule:
+			err = client.DeleteRule(ctx, entityName, name)
+		case NodeTypeQueue:
+			err = client.DeleteQueue(ctx, entityName)
+		case NodeTypeTopic:
+			err = client.DeleteTopic(ctx, entityName)
+		case NodeTypeSubscription:
+			err = client.DeleteSubscription(ctx, entityName)
+		}
+
+		return EntityDeletedMsg{EntityName: entityName, Err: err}
+	}
+}
@@ -0,0 +1,307 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/MonsieurTib/service-bus-tui/internal/azure"
+	"github.com/MonsieurTib/service-bus-tui/internal/styles"
+	"github.com/charmbracelet/bubbles/spinner"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/muesli/reflow/truncate"
+	"github.com/muesli/reflow/wordwrap"
+)
+
+type pickerEntity struct {
+	name     string
+	nodeType string // NodeTypeTopic or NodeTypeQueue
+}
+
+// EntityPickerModel picks a topic or queue to send to, in the current namespace or another one.
+type EntityPickerModel struct {
+	title          string
+	client         *azure.ServiceBusClient
+	home           *azure.ServiceBusClient // the client the picker was opened with, owned by the caller
+	entities       []pickerEntity
+	filtered       []pickerEntity
+	filterInput    textinput.Model
+	namespaceInput textinput.Model
+	selectedIdx    int
+	spinner        spinner.Model
+	isLoading      bool
+	errMsg         string
+	width          int
+	height         int
+}
+
+type PickerEntitiesLoadedMsg struct {
+	Client   *azure.ServiceBusClient
+	Entities []pickerEntity
+	Err      error
+}
+
+// EntityPickedMsg is sent when the picker is closed; Client is nil when it was cancelled. A Client of another
+// namespace than the one the picker was opened with belongs to the receiver, which closes it once done.
+type EntityPickedMsg struct {
+	Client     *azure.ServiceBusClient
+	EntityName string
+}
+
+func NewEntityPickerModel(title string, client *azure.ServiceBusClient) *EntityPickerModel {
+	s := spinner.New()
+	s.Spinner = spinner.MiniDot
+
+	filter := textinput.New()
+	filter.Prompt = "Filter: "
+	filter.Placeholder = "type to filter"
+	filter.Focus()
+
+	ns := textinput.New()
+	ns.Prompt = "Namespace: "
+	ns.Placeholder = "namespace name or connection string"
+
+	return &EntityPickerModel{
+		title:          title,
+		client:         client,
+		home:           client,
+		filterInput:    filter,
+		namespaceInput: ns,
+		spinner:        s,
+	}
+}
+
+func (m *EntityPickerModel) Init() tea.Cmd {
+	return m.load(m.client)
+}
+
+func (m *EntityPickerModel) load(client *azure.ServiceBusClient) tea.Cmd {
+	m.isLoading = true
+	m.errMsg = ""
+	return tea.Batch(m.spinner.Tick, loadPickerEntitiesCmd(client))
+}
+
+func (m *EntityPickerModel) Update(msg tea.Msg) (*EntityPickerModel, tea.Cmd) {
+	var spinnerCmd tea.Cmd
+	m.spinner, spinnerCmd = m.spinner.Update(msg)
+
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		if m.namespaceInput.Focused() {
+			return m, m.updateNamespaceInput(msg)
+		}
+
+		switch msg.String() {
+		case "esc":
+			return m, tea.Batch(m.closeOtherClient(), func() tea.Msg { return EntityPickedMsg{} })
+		case "up", "ctrl+p":
+			if m.selectedIdx > 0 {
+				m.selectedIdx--
+			}
+			return m, nil
+		case "down", "ctrl+n":
+			if m.selectedIdx < len(m.filtered)-1 {
+				m.selectedIdx++
+			}
+			return m, nil
+		case "ctrl+o":
+			m.filterInput.Blur()
+			m.namespaceInput.SetValue("")
+			return m, m.namespaceInput.Focus()
+		case "enter":
+			if m.isLoading || m.selectedIdx >= len(m.filtered) {
+				return m, nil
+			}
+			picked := EntityPickedMsg{Client: m.client, EntityName: m.filtered[m.selectedIdx].name}
+			return m, func() tea.Msg { return picked }
+		}
+
+		var cmd tea.Cmd
+		m.filterInput, cmd = m.filterInput.Update(msg)
+		m.applyFilter()
+		return m, cmd
+
+	case PickerEntitiesLoadedMsg:
+		m.isLoading = false
+		if msg.Err != nil {
+			m.errMsg = msg.Err.Error()
+			break
+		}
+		var closeCmd tea.Cmd
+		if msg.Client != m.client {
+			closeCmd = m.closeOtherClient()
+		}
+		m.client = msg.Client
+		m.entities = msg.Entities
+		m.filterInput.SetValue("")
+		m.applyFilter()
+		return m, closeCmd
+	}
+
+	if m.isLoading {
+		return m, spinnerCmd
+	}
+	return m, nil
+}
+
+func (m *EntityPickerModel) updateNamespaceInput(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "esc":
+		m.namespaceInput.Blur()
+		return m.filterInput.Focus()
+	case "enter":
+		value := strings.TrimSpace(m.namespaceInput.Value())
+		if value == "" {
+			return nil
+		}
+		m.namespaceInput.Blur()
+		m.filterInput.Focus()
+		m.isLoading = true
+		m.errMsg = ""
+		return tea.Batch(m.spinner.Tick, connectPickerNamespaceCmd(m.client, value))
+	}
+
+	var cmd tea.Cmd
+	m.namespaceInput, cmd = m.namespaceInput.Update(msg)
+	return cmd
+}
+
+// closeOtherClient closes the client of the other namespace the picker switched to, if any.
+func (m *EntityPickerModel) closeOtherClient() tea.Cmd {
+	if m.client == m.home {
+		return nil
+	}
+	return closeClientCmd(m.client)
+}
+
+func (m *EntityPickerModel) applyFilter() {
+	filter := strings.ToLower(strings.TrimSpace(m.filterInput.Value()))
+
+	m.filtered = m.filtered[:0]
+	for _, e := range m.entities {
+		if filter == "" || strings.Contains(strings.ToLower(e.name), filter) {
+			m.filtered = append(m.filtered, e)
+		}
+	}
+	m.selectedIdx = min(m.selectedIdx, max(len(m.filtered)-1, 0))
+}
+
+func (m *EntityPickerModel) SetSize(width, height int) {
+	m.width = width
+	m.height = height
+	m.filterInput.Width = max(width-10, 10)
+	m.namespaceInput.Width = max(width-13, 10)
+}
+
+func (m *EntityPickerModel) View() string {
+	var s strings.Builder
+
+	s.WriteString(detailHeaderStyle.Render(fmt.Sprintf("%s (namespace: %s)", m.title, m.client.GetNamespace())))
+	s.WriteString("\n")
+
+	if m.namespaceInput.Focused() {
+		s.WriteString(m.namespaceInput.View())
+	} else {
+		s.WriteString(m.filterInput.View())
+	}
+	s.WriteString("\n\n")
+
+	switch {
+	case m.isLoading:
+		s.WriteString(m.spinner.View())
+		s.WriteString(" ")
+		s.WriteString(styles.Subtle.Render("Loading topics and queues..."))
+		s.WriteString("\n")
+	case m.errMsg != "":
+		s.WriteString(styles.Error.Render(wordwrap.String(m.errMsg, max(m.width, 10))))
+		s.WriteString("\n")
+	case len(m.filtered) == 0:
+		s.WriteString(styles.Subtle.Render("No matching topics or queues"))
+		s.WriteString("\n")
+	default:
+		m.viewEntities(&s)
+	}
+
+	s.WriteString("\n")
+	s.WriteString(styles.Subtle.Render("↑↓: navigate • enter: send • ctrl+o: other namespace • esc: cancel"))
+	return s.String()
+}
+
+func (m *EntityPickerModel) viewEntities(s *strings.Builder) {
+	// Reserve: title (1) + filter (1) + blank (1) + blank and footer (2)
+	visible := max(m.height-5, 3)
+	start := max(m.selectedIdx-visible+1, 0)
+	end := min(start+visible, len(m.filtered))
+
+	for i := start; i < end; i++ {
+		e := m.filtered[i]
+		icon := "›"
+		if e.nodeType == NodeTypeQueue {
+			icon = "□"
+		}
+
+		line := fmt.Sprintf("%s %s", icon, e.name)
+		if m.width > 2 {
+			line = truncate.StringWithTail(line, uint(m.width-2), "…")
+		}
+
+		if i == m.selectedIdx {
+			s.WriteString(styles.Selected.Render("▶ " + line))
+		} else {
+			s.WriteString("  " + line)
+		}
+		s.WriteString("\n")
+	}
+}
+
+func loadPickerEntitiesCmd(client *azure.ServiceBusClient) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), defaultContextTimeout)
+		defer cancel()
+
+		topics, err := client.ListTopics(ctx)
+		if err != nil {
+			return PickerEntitiesLoadedMsg{Err: fmt.Errorf("failed to load topics: %w", err)}
+		}
+
+		queues, err := client.ListQueues(ctx)
+		if err != nil {
+			return PickerEntitiesLoadedMsg{Err: fmt.Errorf("failed to load queues: %w", err)}
+		}
+
+		entities := make([]pickerEntity, 0, len(topics)+len(queues))
+		for _, topic := range topics {
+			entities = append(entities, pickerEntity{name: topic, nodeType: NodeTypeTopic})
+		}
+		for _, queue := range queues {
+			entities = append(entities, pickerEntity{name: queue, nodeType: NodeTypeQueue})
+		}
+
+		return PickerEntitiesLoadedMsg{Client: client, Entities: entities}
+	}
+}
+
+// connectPickerNamespaceCmd connects to another namespace, from a connection string or by name with the
+// credential of client, and lists its topics and queues.
+func connectPickerNamespaceCmd(client *azure.ServiceBusClient, value string) tea.Cmd {
+	return func() tea.Msg {
+		var other *azure.ServiceBusClient
+		var err error
+
+		if strings.HasPrefix(strings.ToLower(value), "endpoint=") {
+			other, err = azure.NewServiceBusClientFromConnectionString(value)
+		} else {
+			other, err = client.WithNamespace(value)
+		}
+		if err != nil {
+			return PickerEntitiesLoadedMsg{Err: fmt.Errorf("failed to connect: %w", err)}
+		}
+
+		loaded := loadPickerEntitiesCmd(other)().(PickerEntitiesLoadedMsg)
+		if loaded.Err != nil {
+			closeClientCmd(other)()
+		}
+		return loaded
+	}
+}
@@ -1,6 +1,7 @@
 package app
 
 import (
+	"log"
 	"strings"
 
 	"github.com/MonsieurTib/service-bus-tui/internal/azure"
@@ -19,9 +20,15 @@ const (
 
 // ExplorerModel "orchestrates" the namespace tree, messages panel, and detail panel.
 type ExplorerModel struct {
+	client        *azure.ServiceBusClient
 	namespace     *NamespaceModel
 	messages      *MessagesModel
 	detail        *MessageDetailModel
+	composer      *ComposerModel
+	entityForm    *EntityFormModel
+	ruleForm      *RuleFormModel
+	summary       *DLQSummaryModel
+	sasPolicies   *SASPoliciesModel
 	activePane    Pane
 	width         int
 	height        int
@@ -31,6 +38,7 @@ type ExplorerModel struct {
 
 func NewExplorerModel(namespaceName string, client *azure.ServiceBusClient) *ExplorerModel {
 	return &ExplorerModel{
+		client:        client,
 		namespace:     NewNamespaceModel(namespaceName, client),
 		messages:      NewMessagesModel(client),
 		detail:        NewMessageDetailModel(),
@@ -63,12 +71,55 @@ func (m *ExplorerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 		m.messages.SetSize(m.messagesWidth()-2, m.contentHeight())
 		m.detail.SetSize(m.detailWidth()-2, m.contentHeight())
+		if m.composer != nil {
+			m.composer.SetSize(m.composerWidth()-2, m.contentHeight())
+		}
+		if m.entityForm != nil {
+			m.entityForm.SetSize(m.composerWidth()-2, m.contentHeight())
+		}
+		if m.ruleForm != nil {
+			m.ruleForm.SetSize(m.composerWidth()-2, m.contentHeight())
+		}
+		if m.summary != nil {
+			m.summary.SetSize(m.composerWidth()-2, m.contentHeight())
+		}
+		if m.sasPolicies != nil {
+			m.sasPolicies.SetSize(m.composerWidth()-2, m.contentHeight())
+		}
 
 	case tea.KeyMsg:
+		if m.composer != nil {
+			var cmd tea.Cmd
+			m.composer, cmd = m.composer.Update(msg)
+			return m, cmd
+		}
+		if m.entityForm != nil {
+			var cmd tea.Cmd
+			m.entityForm, cmd = m.entityForm.Update(msg)
+			return m, cmd
+		}
+		if m.ruleForm != nil {
+			var cmd tea.Cmd
+			m.ruleForm, cmd = m.ruleForm.Update(msg)
+			return m, cmd
+		}
+		if m.summary != nil {
+			var cmd tea.Cmd
+			m.summary, cmd = m.summary.Update(msg)
+			return m, cmd
+		}
+		if m.sasPolicies != nil {
+			var cmd tea.Cmd
+			m.sasPolicies, cmd = m.sasPolicies.Update(msg)
+			return m, cmd
+		}
+
 		switch msg.String() {
 		case "tab":
-			m.switchPane()
-			return m, nil
+			if !m.messages.isPrompting() && !m.namespace.isPrompting() {
+				m.switchPane()
+				return m, nil
+			}
 		}
 
 		switch m.activePane {
@@ -94,6 +145,9 @@ func (m *ExplorerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		cmds = append(cmds, cmd)
 
 	case MessagesLoadedMsg:
+		if !m.messages.isCurrentLoad(msg.EntityName, msg.IsDeadLetter, msg.loadGen) {
+			break
+		}
 		var msgsModel tea.Model
 		msgsModel, msgsCmd := m.messages.Update(msg)
 		m.messages = msgsModel.(*MessagesModel)
@@ -106,6 +160,111 @@ func (m *ExplorerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.syncDetailWithCursor()
 		}
 
+	case EntityPropertiesLoadedMsg:
+		if msg.Err != nil {
+			log.Printf("failed to load properties of %s: %v", msg.EntityName, msg.Err)
+		} else if node := m.namespace.selectedNode(); m.activePane == PaneNamespace && node != nil && node.ID == msg.NodeID {
+			// The cursor may have moved on while the properties were loading.
+			m.detail.SetEntityProperties(msg.Properties)
+		}
+
+	case ComposeSelectedMsg:
+		m.composer = NewComposerModel(m.client, msg.EntityName)
+		m.composer.SetSize(m.composerWidth()-2, m.contentHeight())
+		cmds = append(cmds, m.composer.Init())
+
+	case ComposerClosedMsg:
+		m.composer = nil
+
+	case EntityFormRequestedMsg:
+		m.entityForm = NewEntityFormModel(m.client, msg)
+		m.entityForm.SetSize(m.composerWidth()-2, m.contentHeight())
+		cmds = append(cmds, m.entityForm.Init())
+
+	case EntityFormClosedMsg:
+		m.entityForm = nil
+
+	case EntitySavedMsg:
+		if m.entityForm == nil {
+			break
+		}
+		if msg.Err != nil {
+			var cmd tea.Cmd
+			m.entityForm, cmd = m.entityForm.Update(msg)
+			cmds = append(cmds, cmd)
+			break
+		}
+		m.entityForm = nil
+		cmds = append(cmds, m.namespace.refresh())
+
+	case RuleFormRequestedMsg:
+		m.ruleForm = NewRuleFormModel(m.client, msg)
+		m.ruleForm.SetSize(m.composerWidth()-2, m.contentHeight())
+		cmds = append(cmds, m.ruleForm.Init())
+
+	case RuleFormClosedMsg:
+		m.ruleForm = nil
+
+	case RuleSavedMsg:
+		if m.ruleForm == nil {
+			break
+		}
+		if msg.Err != nil {
+			var cmd tea.Cmd
+			m.ruleForm, cmd = m.ruleForm.Update(msg)
+			cmds = append(cmds, cmd)
+			break
+		}
+		m.ruleForm = nil
+		cmds = append(cmds, m.namespace.refresh())
+
+	case RuleFocusedMsg:
+		if m.activePane == PaneNamespace {
+			m.detail.SetRule(msg.EntityName, msg.Rule)
+		}
+
+	case DLQSummaryRequestedMsg:
+		m.summary = NewDLQSummaryModel(m.client, msg.EntityName)
+		m.summary.SetSize(m.composerWidth()-2, m.contentHeight())
+		cmds = append(cmds, m.summary.Init())
+
+	case DLQSummaryClosedMsg:
+		m.summary = nil
+
+	case DLQGroupSelectedMsg:
+		m.summary = nil
+		m.activePane = PaneMessages
+		m.messages.SetFocused(true)
+		m.prevCursor = -1
+		cmds = append(cmds, m.messages.LoadGroup(msg.EntityName, msg.Group))
+
+	case SASPoliciesRequestedMsg:
+		m.sasPolicies = NewSASPoliciesModel(m.client, m.namespaceName)
+		m.sasPolicies.SetSize(m.composerWidth()-2, m.contentHeight())
+		cmds = append(cmds, m.sasPolicies.Init())
+
+	case SASPoliciesClosedMsg:
+		m.sasPolicies = nil
+
+	case CorrelationRuleDraftedMsg:
+		m.namespace.pickRuleTarget(msg.Rule)
+		m.activePane = PaneNamespace
+		m.messages.SetFocused(false)
+
+	case TestRuleMsg:
+		m.messages.SetRuleFilter(msg.Rule)
+
+	case PurgeCompletedMsg:
+		var nsModel tea.Model
+		nsModel, nsCmd := m.namespace.Update(msg)
+		m.namespace = nsModel.(*NamespaceModel)
+		cmds = append(cmds, nsCmd)
+
+		// Refresh the messages pane when it shows the purged entity.
+		if !m.messages.isEmpty && m.messages.entityName == msg.EntityName && m.messages.isDeadLetter == msg.IsDeadLetter {
+			cmds = append(cmds, m.messages.LoadMessages(msg.EntityName, msg.IsDeadLetter))
+		}
+
 	case ErrorMsg:
 		var msgsModel tea.Model
 		msgsModel, msgsCmd := m.messages.Update(msg)
@@ -122,6 +281,32 @@ func (m *ExplorerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		msgsModel, msgsCmd := m.messages.Update(msg)
 		m.messages = msgsModel.(*MessagesModel)
 		cmds = append(cmds, msgsCmd)
+
+		if m.composer != nil {
+			var composerCmd tea.Cmd
+			m.composer, composerCmd = m.composer.Update(msg)
+			cmds = append(cmds, composerCmd)
+		}
+		if m.entityForm != nil {
+			var formCmd tea.Cmd
+			m.entityForm, formCmd = m.entityForm.Update(msg)
+			cmds = append(cmds, formCmd)
+		}
+		if m.ruleForm != nil {
+			var formCmd tea.Cmd
+			m.ruleForm, formCmd = m.ruleForm.Update(msg)
+			cmds = append(cmds, formCmd)
+		}
+		if m.summary != nil {
+			var summaryCmd tea.Cmd
+			m.summary, summaryCmd = m.summary.Update(msg)
+			cmds = append(cmds, summaryCmd)
+		}
+		if m.sasPolicies != nil {
+			var sasCmd tea.Cmd
+			m.sasPolicies, sasCmd = m.sasPolicies.Update(msg)
+			cmds = append(cmds, sasCmd)
+		}
 	}
 
 	return m, tea.Batch(cmds...)
@@ -133,6 +318,9 @@ func (m *ExplorerModel) switchPane() {
 		if !m.messages.isEmpty {
 			m.activePane = PaneMessages
 			m.messages.SetFocused(true)
+			// The detail pane may be showing entity properties, so bring the selected message back.
+			m.prevCursor = -1
+			m.syncDetailWithCursor()
 		}
 	case PaneMessages:
 		if !m.messages.isEmpty && m.messages.SelectedMessage() != nil {
@@ -173,6 +361,29 @@ func (m *ExplorerModel) View() string {
 	treeContent := m.namespace.ViewContent()
 	treeContent = padToHeight(treeContent, contentHeight)
 
+	if m.composer != nil || m.entityForm != nil || m.ruleForm != nil || m.summary != nil || m.sasPolicies != nil {
+		overlay, hint := m.overlayContent()
+
+		treeStyle := lipgloss.NewStyle().
+			Border(lipgloss.RoundedBorder()).
+			BorderForeground(styles.Muted).
+			Width(treeWidth - 2)
+
+		composerStyle := lipgloss.NewStyle().
+			Border(lipgloss.RoundedBorder()).
+			BorderForeground(styles.Primary).
+			Width(m.composerWidth() - 2)
+
+		s.WriteString(lipgloss.JoinHorizontal(lipgloss.Top,
+			treeStyle.Render(treeContent),
+			composerStyle.Render(padToHeight(overlay, contentHeight)),
+		))
+		s.WriteString("\n")
+		s.WriteString(styles.Subtle.Render(hint))
+		s.WriteString("\n")
+		return s.String()
+	}
+
 	messagesContent := m.messages.ViewContent()
 	messagesContent = padToHeight(messagesContent, contentHeight)
 
@@ -213,12 +424,31 @@ func (m *ExplorerModel) View() string {
 	s.WriteString(lipgloss.JoinHorizontal(lipgloss.Top, leftPane, middlePane, rightPane))
 	s.WriteString("\n")
 
-	s.WriteString(styles.Subtle.Render("tab: switch pane • ↑↓/jk: navigate • ctrl+c: quit"))
+	if m.namespace.ruleDraft != nil {
+		s.WriteString(styles.Selected.Render("Pick the subscription of the new rule • enter: choose • esc: cancel"))
+	} else {
+		s.WriteString(styles.Subtle.Render("tab: switch pane • ↑↓/jk: navigate • ctrl+c: quit"))
+	}
 	s.WriteString("\n")
 
 	return s.String()
 }
 
+// overlayContent returns the view and footer hint of the composer or form that is open.
+func (m *ExplorerModel) overlayContent() (string, string) {
+	switch {
+	case m.composer != nil:
+		return m.composer.View(), "esc: close composer • ctrl+c: quit"
+	case m.entityForm != nil:
+		return m.entityForm.View(), "esc: close form • ctrl+c: quit"
+	case m.summary != nil:
+		return m.summary.View(), "esc: stop or close summary • ctrl+c: quit"
+	case m.sasPolicies != nil:
+		return m.sasPolicies.View(), "esc: close SAS policies • ctrl+c: quit"
+	}
+	return m.ruleForm.View(), "esc: close form • ctrl+c: quit"
+}
+
 func (m *ExplorerModel) contentHeight() int {
 	// Reserve: Namespace header (1) + Footer (1) + borders (2) + extra (1)
 	reserved := 5
@@ -237,6 +467,11 @@ func (m *ExplorerModel) messagesWidth() int {
 	return w
 }
 
+// composerWidth spans the messages and detail panes, which the composer or a form replaces while open.
+func (m *ExplorerModel) composerWidth() int {
+	return m.messagesWidth() + m.detailWidth()
+}
+
 func (m *ExplorerModel) detailWidth() int {
 	w := max(m.width*30/100, 30)
 	return w
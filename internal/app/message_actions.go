@@ -0,0 +1,370 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/MonsieurTib/service-bus-tui/internal/azure"
+	"github.com/MonsieurTib/service-bus-tui/internal/styles"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/muesli/reflow/truncate"
+	"github.com/muesli/reflow/wordwrap"
+)
+
+const messageActionTimeout = 2 * time.Minute
+
+// messageAction tracks an operation running against the marked (or selected) messages.
+type messageAction struct {
+	title      string // e.g. "Resubmit"
+	targets    []int64
+	confirming bool
+	warning    string // shown with the confirmation
+	editing    bool   // waiting for $EDITOR to return
+	running    bool
+	run        tea.Cmd // started once the action is confirmed
+	results    []azure.MessageResult
+	err        error // why the action stopped before processing every target
+}
+
+type MessageActionCompletedMsg struct {
+	Results []azure.MessageResult
+	Err     error
+}
+
+// resubmitCheckedMsg reports whether the parent of the dead-letter queue has duplicate detection, before resubmitting.
+type resubmitCheckedMsg struct {
+	DuplicateDetection bool
+}
+
+func (m *MessagesModel) isActionRunning() bool {
+	return m.action != nil && m.action.running
+}
+
+func (m *MessagesModel) toggleMarked() {
+	cursor := m.table.Cursor()
+	if cursor < 0 || cursor >= len(m.messages) {
+		return
+	}
+
+	msg := m.messages[cursor]
+	if m.marked[msg.SequenceNumber] {
+		delete(m.marked, msg.SequenceNumber)
+	} else {
+		m.marked[msg.SequenceNumber] = true
+	}
+
+	m.rows[cursor] = messageRow(msg, m.marked[msg.SequenceNumber], m.getBodyColumnWidth())
+	m.table.SetRows(m.rows)
+}
+
+// targetSequenceNumbers returns the marked messages, or the selected one when nothing is marked.
+func (m *MessagesModel) targetSequenceNumbers() []int64 {
+	if len(m.marked) > 0 {
+		targets := make([]int64, 0, len(m.marked))
+		for seq := range m.marked {
+			targets = append(targets, seq)
+		}
+		slices.Sort(targets)
+		return targets
+	}
+
+	if selected := m.SelectedMessage(); selected != nil {
+		return []int64{selected.SequenceNumber}
+	}
+	return nil
+}
+
+func (m *MessagesModel) resubmit() tea.Cmd {
+	if !m.isDeadLetter {
+		return nil
+	}
+
+	targets := m.targetSequenceNumbers()
+	if len(targets) == 0 {
+		return nil
+	}
+
+	m.action = &messageAction{
+		title:   "Resubmit",
+		targets: targets,
+		running: true,
+		run:     m.resubmitCmd(targets),
+	}
+
+	return tea.Batch(
+		m.spinner.Tick,
+		m.checkDuplicateDetectionCmd(),
+	)
+}
+
+// handleResubmitChecked resubmits right away, unless the parent has duplicate detection: copies keep the MessageID
+// of the original, so the broker may drop them while the originals are completed, and the user has to confirm.
+func (m *MessagesModel) handleResubmitChecked(msg resubmitCheckedMsg) tea.Cmd {
+	if m.action == nil || !m.action.running {
+		return nil
+	}
+	if !msg.DuplicateDetection {
+		return m.action.run
+	}
+
+	m.action.running = false
+	m.action.confirming = true
+	m.action.warning = fmt.Sprintf("%s has duplicate detection: copies keep their MessageID, so a copy sent within the "+
+		"detection window of its original is dropped by the broker, while the dead-lettered original is still completed "+
+		"and lost.", azure.ParentEntityName(m.entityName))
+	return nil
+}
+
+// editAndResend opens the selected message in $EDITOR and sends the saved result to the parent queue or topic.
+func (m *MessagesModel) editAndResend() tea.Cmd {
+	selected := m.SelectedMessage()
+	if selected == nil {
+		return nil
+	}
+
+	m.action = &messageAction{
+		title:   "Resend",
+		targets: []int64{selected.SequenceNumber},
+		editing: true,
+		running: true,
+	}
+	return editMessageCmd(*selected)
+}
+
+func (m *MessagesModel) handleMessageEdited(msg MessageEditedMsg) tea.Cmd {
+	if m.action == nil || !m.action.editing {
+		return nil
+	}
+	m.action.editing = false
+
+	switch {
+	case msg.Err != nil:
+		m.action.running = false
+		m.action.results = []azure.MessageResult{{SequenceNumber: m.action.targets[0], Err: msg.Err}}
+		return nil
+	case msg.Cancelled:
+		m.action = nil
+		return nil
+	}
+
+	return tea.Batch(
+		m.spinner.Tick,
+		sendCopyCmd(m.client, azure.ParentEntityName(m.entityName), m.action.targets[0], msg.Message),
+	)
+}
+
+// openSendCopyPicker asks for the topic or queue the selected message is copied to.
+func (m *MessagesModel) openSendCopyPicker() tea.Cmd {
+	if m.SelectedMessage() == nil {
+		return nil
+	}
+
+	m.picker = NewEntityPickerModel("Send copy to", m.client)
+	m.picker.SetSize(m.width, m.height)
+	return m.picker.Init()
+}
+
+// sendCopy sends the selected message to the entity picked in the picker.
+func (m *MessagesModel) sendCopy(picked EntityPickedMsg) tea.Cmd {
+	selected := m.SelectedMessage()
+	if picked.Client == nil {
+		return nil
+	}
+	// A client of another namespace is only used for this copy.
+	var closeCmd tea.Cmd
+	if picked.Client != m.client {
+		closeCmd = closeClientCmd(picked.Client)
+	}
+	if selected == nil {
+		return closeCmd
+	}
+
+	m.action = &messageAction{
+		title:   fmt.Sprintf("Send copy to %s/%s", picked.Client.GetNamespace(), picked.EntityName),
+		targets: []int64{selected.SequenceNumber},
+		running: true,
+	}
+
+	return tea.Batch(
+		m.spinner.Tick,
+		tea.Sequence(sendCopyCmd(picked.Client, picked.EntityName, selected.SequenceNumber, *selected), closeCmd),
+	)
+}
+
+// deleteMessages asks for confirmation before completing the target messages.
+func (m *MessagesModel) deleteMessages() tea.Cmd {
+	targets := m.targetSequenceNumbers()
+	if len(targets) == 0 {
+		return nil
+	}
+
+	m.action = &messageAction{
+		title:      "Delete",
+		targets:    targets,
+		confirming: true,
+		run:        m.deleteCmd(targets),
+	}
+	return nil
+}
+
+func (m *MessagesModel) updateAction(msg tea.KeyMsg) tea.Cmd {
+	if m.action.running {
+		return nil
+	}
+
+	if m.action.confirming {
+		switch msg.String() {
+		case "y":
+			m.action.confirming = false
+			m.action.running = true
+			return tea.Batch(m.spinner.Tick, m.action.run)
+		case "n", "esc":
+			m.action = nil
+		}
+		return nil
+	}
+
+	switch msg.String() {
+	case "esc", "enter":
+		// The entity has changed, so peek it again from the start.
+		m.action = nil
+		return m.peekFrom(0)
+	}
+	return nil
+}
+
+func (m *MessagesModel) viewAction() string {
+	var s strings.Builder
+
+	if m.action.confirming {
+		source := m.entityName
+		if m.isDeadLetter {
+			source += " (DLQ)"
+		}
+		s.WriteString(detailHeaderStyle.Render(fmt.Sprintf("%s %d messages from %s?", m.action.title, len(m.action.targets), source)))
+		s.WriteString("\n\n")
+
+		seqs := make([]string, 0, len(m.action.targets))
+		for _, seq := range m.action.targets {
+			seqs = append(seqs, fmt.Sprintf("%d", seq))
+		}
+		s.WriteString(wordwrap.String(strings.Join(seqs, ", "), max(m.width, 10)))
+		s.WriteString("\n\n")
+		if m.action.warning != "" {
+			s.WriteString(styles.Error.Render(wordwrap.String(m.action.warning, max(m.width, 10))))
+			s.WriteString("\n\n")
+		}
+		s.WriteString(styles.Subtle.Render("y: confirm • n/esc: cancel"))
+		return s.String()
+	}
+
+	if m.action.running {
+		s.WriteString(m.spinner.View())
+		s.WriteString(" ")
+		s.WriteString(styles.Subtle.Render(fmt.Sprintf("%s: processing %d messages...", m.action.title, len(m.action.targets))))
+		return s.String()
+	}
+
+	failed := 0
+	for _, r := range m.action.results {
+		if r.Err != nil {
+			failed++
+		}
+	}
+
+	s.WriteString(detailHeaderStyle.Render(fmt.Sprintf("%s: %d succeeded, %d failed", m.action.title, len(m.action.results)-failed, failed)))
+	s.WriteString("\n\n")
+	if m.action.err != nil {
+		s.WriteString(styles.Error.Render(wordwrap.String("Stopped: "+m.action.err.Error(), max(m.width, 10))))
+		s.WriteString("\n\n")
+	}
+
+	for _, r := range m.action.results {
+		var line string
+		if r.Err != nil {
+			line = styles.Error.Render(fmt.Sprintf("✗ %d: %v", r.SequenceNumber, r.Err))
+		} else {
+			line = styles.Selected.Render(fmt.Sprintf("✓ %d", r.SequenceNumber))
+		}
+		if m.width > 0 {
+			line = truncate.StringWithTail(line, uint(m.width), "…")
+		}
+		s.WriteString(line)
+		s.WriteString("\n")
+	}
+
+	s.WriteString("\n")
+	s.WriteString(styles.Subtle.Render("enter/esc: close and refresh"))
+	return s.String()
+}
+
+func (m *MessagesModel) resubmitCmd(sequenceNumbers []int64) tea.Cmd {
+	client := m.client
+	entityName := m.entityName
+
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), messageActionTimeout)
+		defer cancel()
+
+		// Some messages may have been resubmitted before an error, so results are shown whenever there are some.
+		results, err := client.ResubmitDeadLetterMessages(ctx, entityName, sequenceNumbers)
+		if err != nil && len(results) == 0 {
+			return ErrorMsg(fmt.Sprintf("failed to resubmit messages: %v", err))
+		}
+
+		return MessageActionCompletedMsg{Results: results, Err: err}
+	}
+}
+
+// checkDuplicateDetectionCmd looks up whether the parent of the dead-letter queue has duplicate detection. When it
+// can't be looked up, e.g. without Manage rights, resubmitting goes ahead as before.
+func (m *MessagesModel) checkDuplicateDetectionCmd() tea.Cmd {
+	client := m.client
+	entityName := m.entityName
+
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), defaultContextTimeout)
+		defer cancel()
+
+		duplicateDetection, err := client.ParentRequiresDuplicateDetection(ctx, entityName)
+		if err != nil {
+			log.Printf("failed to check duplicate detection of %s: %v", azure.ParentEntityName(entityName), err)
+		}
+		return resubmitCheckedMsg{DuplicateDetection: duplicateDetection}
+	}
+}
+
+func (m *MessagesModel) deleteCmd(sequenceNumbers []int64) tea.Cmd {
+	client := m.client
+	entityName := m.entityName
+	isDeadLetter := m.isDeadLetter
+
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), messageActionTimeout)
+		defer cancel()
+
+		results, err := client.DeleteMessages(ctx, entityName, isDeadLetter, sequenceNumbers)
+		if err != nil && len(results) == 0 {
+			return ErrorMsg(fmt.Sprintf("failed to delete messages: %v", err))
+		}
+
+		return MessageActionCompletedMsg{Results: results, Err: err}
+	}
+}
+
+// sendCopyCmd sends msg to entityName and reports the outcome against the sequence number it was copied from.
+func sendCopyCmd(client *azure.ServiceBusClient, entityName string, sequenceNumber int64, msg azure.MessageInfo) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), defaultContextTimeout)
+		defer cancel()
+
+		err := client.SendMessage(ctx, entityName, msg)
+		return MessageActionCompletedMsg{
+			Results: []azure.MessageResult{{SequenceNumber: sequenceNumber, Err: err}},
+		}
+	}
+}
@@ -4,12 +4,14 @@ import (
 	"fmt"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/MonsieurTib/service-bus-tui/internal/azure"
 	"github.com/MonsieurTib/service-bus-tui/internal/styles"
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/reflow/wordwrap"
 )
 
 var (
@@ -28,6 +30,9 @@ var (
 type MessageDetailModel struct {
 	viewport viewport.Model
 	message  *azure.MessageInfo
+	entity   *azure.EntityProperties
+	rule     *azure.RuleInfo
+	ruleOf   string // subscription of rule
 	width    int
 	height   int
 	ready    bool
@@ -39,6 +44,25 @@ func NewMessageDetailModel() *MessageDetailModel {
 
 func (m *MessageDetailModel) SetMessage(msg *azure.MessageInfo) {
 	m.message = msg
+	m.entity = nil
+	m.rule = nil
+	m.rebuildContent()
+}
+
+// SetEntityProperties shows the properties of a queue, topic or subscription instead of a message.
+func (m *MessageDetailModel) SetEntityProperties(props *azure.EntityProperties) {
+	m.entity = props
+	m.message = nil
+	m.rule = nil
+	m.rebuildContent()
+}
+
+// SetRule shows the filter and action of a rule of the subscription entityName.
+func (m *MessageDetailModel) SetRule(entityName string, rule azure.RuleInfo) {
+	m.rule = &rule
+	m.ruleOf = entityName
+	m.message = nil
+	m.entity = nil
 	m.rebuildContent()
 }
 
@@ -77,7 +101,7 @@ func (m *MessageDetailModel) Update(msg tea.Msg) tea.Cmd {
 }
 
 func (m *MessageDetailModel) ViewContent() string {
-	if m.message == nil {
+	if m.message == nil && m.entity == nil && m.rule == nil {
 		return styles.Subtle.Render("No message selected")
 	}
 
@@ -89,6 +113,14 @@ func (m *MessageDetailModel) ViewContent() string {
 }
 
 func (m *MessageDetailModel) rebuildContent() {
+	if m.entity != nil && m.ready {
+		m.rebuildEntityContent()
+		return
+	}
+	if m.rule != nil && m.ready {
+		m.rebuildRuleContent()
+		return
+	}
 	if m.message == nil || !m.ready {
 		return
 	}
@@ -100,11 +132,41 @@ func (m *MessageDetailModel) rebuildContent() {
 	b.WriteString(detailSeparator)
 	b.WriteString("\n")
 
-	writeField(&b, "Message ID", m.message.MessageID)
-	writeField(&b, "Sequence #", fmt.Sprintf("%d", m.message.SequenceNumber))
-	writeField(&b, "Subject", m.message.Subject)
-	writeField(&b, "Enqueued", m.message.EnqueuedTime.Format("2006-01-02 15:04:05"))
-	writeField(&b, "Content-Type", m.message.ContentType)
+	msg := m.message
+	writeField(&b, "Message ID", msg.MessageID)
+	writeField(&b, "Sequence #", fmt.Sprintf("%d", msg.SequenceNumber))
+	writeField(&b, "Subject", msg.Subject)
+	writeField(&b, "Enqueued", msg.EnqueuedTime.Format("2006-01-02 15:04:05"))
+	writeField(&b, "Content-Type", msg.ContentType)
+	writeField(&b, "State", msg.State)
+	writeField(&b, "Delivery count", fmt.Sprintf("%d", msg.DeliveryCount))
+	writeOptionalField(&b, "Correlation ID", msg.CorrelationID)
+	writeOptionalField(&b, "Session ID", msg.SessionID)
+	writeOptionalField(&b, "Reply to", msg.ReplyTo)
+	writeOptionalField(&b, "Reply session", msg.ReplyToSessionID)
+	writeOptionalField(&b, "To", msg.To)
+	writeOptionalField(&b, "Partition key", msg.PartitionKey)
+	if msg.TimeToLive > 0 {
+		writeField(&b, "TTL", msg.TimeToLive.String())
+	}
+	writeOptionalField(&b, "Expires", formatTime(msg.ExpiresAt))
+	writeOptionalField(&b, "Scheduled", formatTime(msg.ScheduledEnqueueTime))
+	writeOptionalField(&b, "Locked until", formatTime(msg.LockedUntil))
+
+	if msg.DeadLetterReason != "" || msg.DeadLetterErrorDescription != "" || msg.DeadLetterSource != "" {
+		b.WriteString("\n")
+		b.WriteString(detailHeaderStyle.Render("Dead Letter"))
+		b.WriteString("\n")
+		b.WriteString(detailSeparator)
+		b.WriteString("\n")
+
+		writeOptionalField(&b, "Reason", msg.DeadLetterReason)
+		writeOptionalField(&b, "Source", msg.DeadLetterSource)
+		if msg.DeadLetterErrorDescription != "" {
+			b.WriteString(wordwrap.String(msg.DeadLetterErrorDescription, max(m.width, 10)))
+			b.WriteString("\n")
+		}
+	}
 
 	if len(m.message.Properties) > 0 {
 		b.WriteString("\n")
@@ -146,3 +208,171 @@ func writeField(b *strings.Builder, label, value string) {
 	b.WriteString(value)
 	b.WriteString("\n")
 }
+
+func (m *MessageDetailModel) rebuildEntityContent() {
+	var b strings.Builder
+	e := m.entity
+
+	b.WriteString(detailHeaderStyle.Render(e.EntityName))
+	b.WriteString("\n")
+	b.WriteString(detailSeparator)
+	b.WriteString("\n")
+
+	writeField(&b, "Status", e.Status)
+	if e.MaxSizeInMegabytes != nil {
+		writeField(&b, "Max size", fmt.Sprintf("%d MB", *e.MaxSizeInMegabytes))
+	}
+	if e.SizeInBytes != nil {
+		writeField(&b, "Size", formatBytes(*e.SizeInBytes))
+	}
+	writeOptionalField(&b, "Default TTL", formatISODuration(e.DefaultMessageTimeToLive))
+	writeOptionalField(&b, "Lock duration", formatISODuration(e.LockDuration))
+	if e.MaxDeliveryCount != nil {
+		writeField(&b, "Max delivery", fmt.Sprintf("%d", *e.MaxDeliveryCount))
+	}
+	if e.RequiresDuplicateDetection != nil {
+		dedup := "disabled"
+		if *e.RequiresDuplicateDetection {
+			dedup = formatISODuration(e.DuplicateDetectionHistoryTimeWindow)
+		}
+		writeField(&b, "Duplicate det.", dedup)
+	}
+	writeOptionalField(&b, "Sessions", formatBool(e.RequiresSession))
+	writeOptionalField(&b, "Partitioning", formatBool(e.EnablePartitioning))
+	if e.ForwardTo != nil || e.ForwardDeadLetteredMessagesTo != nil {
+		writeField(&b, "Forward to", stringValue(e.ForwardTo))
+		writeField(&b, "Forward DLQ to", stringValue(e.ForwardDeadLetteredMessagesTo))
+	}
+
+	b.WriteString("\n")
+	writeField(&b, "Created", formatTime(e.CreatedAt))
+	writeField(&b, "Updated", formatTime(e.UpdatedAt))
+	writeField(&b, "Accessed", formatTime(e.AccessedAt))
+
+	m.viewport.SetContent(b.String())
+	m.viewport.GotoTop()
+}
+
+func (m *MessageDetailModel) rebuildRuleContent() {
+	var b strings.Builder
+	r := m.rule
+
+	b.WriteString(detailHeaderStyle.Render(r.Name))
+	b.WriteString("\n")
+	b.WriteString(detailSeparator)
+	b.WriteString("\n")
+
+	writeField(&b, "Subscription", m.ruleOf)
+	writeField(&b, "Filter", r.FilterType)
+
+	switch r.FilterType {
+	case azure.RuleFilterSQL:
+		b.WriteString("\n")
+		b.WriteString(wordwrap.String(r.SQLExpression, max(m.width, 10)))
+		b.WriteString("\n")
+		writeParameters(&b, r.SQLParameters)
+	case azure.RuleFilterCorrelation:
+		c := r.Correlation
+		writeOptionalField(&b, "Correlation ID", c.CorrelationID)
+		writeOptionalField(&b, "Message ID", c.MessageID)
+		writeOptionalField(&b, "To", c.To)
+		writeOptionalField(&b, "Reply to", c.ReplyTo)
+		writeOptionalField(&b, "Subject", c.Subject)
+		writeOptionalField(&b, "Session ID", c.SessionID)
+		writeOptionalField(&b, "Reply session", c.ReplyToSessionID)
+		writeOptionalField(&b, "Content-Type", c.ContentType)
+		writeParameters(&b, c.Properties)
+	}
+
+	b.WriteString("\n")
+	b.WriteString(detailHeaderStyle.Render("Action"))
+	b.WriteString("\n")
+	b.WriteString(detailSeparator)
+	b.WriteString("\n")
+	if r.ActionExpression == "" {
+		b.WriteString(styles.Subtle.Render("none"))
+		b.WriteString("\n")
+	} else {
+		b.WriteString(wordwrap.String(r.ActionExpression, max(m.width, 10)))
+		b.WriteString("\n")
+		writeParameters(&b, r.ActionParameters)
+	}
+
+	m.viewport.SetContent(b.String())
+	m.viewport.GotoTop()
+}
+
+// writeParameters lists SQL parameters and correlation filter properties, sorted by name.
+func writeParameters(b *strings.Builder, params map[string]any) {
+	keys := make([]string, 0, len(params))
+	for k := range params {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		writeField(b, k, fmt.Sprintf("%v", params[k]))
+	}
+}
+
+// writeOptionalField skips properties that don't apply to the entity.
+func writeOptionalField(b *strings.Builder, label, value string) {
+	if value != "" {
+		writeField(b, label, value)
+	}
+}
+
+// formatISODuration renders an admin API duration such as "PT1M" as "1m0s", and "" when it is unset.
+func formatISODuration(s *string) string {
+	if s == nil {
+		return ""
+	}
+	d, err := azure.ParseISODuration(*s)
+	if err != nil {
+		return *s
+	}
+	switch {
+	case d >= 100*365*24*time.Hour:
+		return "never"
+	case d >= 24*time.Hour && d%(24*time.Hour) == 0:
+		return fmt.Sprintf("%dd", d/(24*time.Hour))
+	}
+	return d.String()
+}
+
+func formatBool(b *bool) string {
+	switch {
+	case b == nil:
+		return ""
+	case *b:
+		return "enabled"
+	}
+	return "disabled"
+}
+
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for v := n / unit; v >= unit; v /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Local().Format("2006-01-02 15:04:05")
+}
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
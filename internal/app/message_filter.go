@@ -0,0 +1,139 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/MonsieurTib/service-bus-tui/internal/azure"
+	"github.com/MonsieurTib/service-bus-tui/internal/sqlfilter"
+	"github.com/MonsieurTib/service-bus-tui/internal/styles"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// messageFilter highlights the loaded messages matched by a SQL expression or a subscription rule. It is
+// evaluated locally, so nothing is received or changed.
+type messageFilter struct {
+	label      string // e.g. "rule high-priority"
+	expression string // the SQL expression typed with "f", reused as the input's initial value
+	match      azure.RuleMatcher
+	matched    int
+	failed     int
+	lastErr    error
+}
+
+// TestRuleMsg highlights the loaded messages the rule would accept.
+type TestRuleMsg struct {
+	Rule azure.RuleInfo
+}
+
+func (m *MessagesModel) openFilterInput() tea.Cmd {
+	m.filterErr = ""
+	value := ""
+	if m.filter != nil {
+		value = m.filter.expression
+	}
+	m.filterInput.SetValue(value)
+	m.filterInput.CursorEnd()
+	return m.filterInput.Focus()
+}
+
+func (m *MessagesModel) updateFilterInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.filterInput.Blur()
+		m.filterErr = ""
+		return m, nil
+	case "enter":
+		expression := strings.TrimSpace(m.filterInput.Value())
+		if expression == "" {
+			m.filterInput.Blur()
+			m.clearFilter()
+			return m, nil
+		}
+
+		f, err := sqlfilter.Parse(expression)
+		if err != nil {
+			m.filterErr = err.Error()
+			return m, nil
+		}
+		m.filterInput.Blur()
+		m.filterErr = ""
+		m.setFilter(&messageFilter{
+			label:      expression,
+			expression: expression,
+			match:      func(msg azure.MessageInfo) (bool, error) { return f.Match(msg) },
+		})
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.filterInput, cmd = m.filterInput.Update(msg)
+	return m, cmd
+}
+
+// SetRuleFilter highlights the messages accepted by rule.
+func (m *MessagesModel) SetRuleFilter(rule azure.RuleInfo) {
+	match, err := azure.NewRuleMatcher(rule)
+	if err != nil {
+		m.filterErr = err.Error()
+		return
+	}
+
+	m.filterErr = ""
+	f := &messageFilter{label: "rule " + rule.Name, match: match}
+	if rule.FilterType == azure.RuleFilterSQL {
+		f.expression = rule.SQLExpression
+	}
+	m.setFilter(f)
+}
+
+func (m *MessagesModel) setFilter(f *messageFilter) {
+	m.filter = f
+	m.applyFilter()
+}
+
+func (m *MessagesModel) clearFilter() {
+	m.filter = nil
+	m.filterErr = ""
+	m.table.SetHighlightedRows()
+}
+
+// applyFilter evaluates the filter against every loaded message and highlights the matches.
+func (m *MessagesModel) applyFilter() {
+	f := m.filter
+	if f == nil {
+		return
+	}
+
+	f.matched, f.failed, f.lastErr = 0, 0, nil
+	var matches []int
+	for i, msg := range m.messages {
+		ok, err := f.match(msg)
+		switch {
+		case err != nil:
+			f.failed++
+			f.lastErr = err
+		case ok:
+			f.matched++
+			matches = append(matches, i)
+		}
+	}
+	m.table.SetHighlightedRows(matches...)
+}
+
+// filterStatus summarises the active filter for the status line.
+func (m *MessagesModel) filterStatus() string {
+	if m.filterErr != "" {
+		return styles.Error.Render(m.filterErr)
+	}
+	f := m.filter
+	if f == nil {
+		return ""
+	}
+
+	status := fmt.Sprintf("%s: %d of %d match", f.label, f.matched, len(m.messages))
+	if f.failed > 0 {
+		return styles.Selected.Render(status) + " " + styles.Error.Render(fmt.Sprintf("(%d failed: %v)", f.failed, f.lastErr))
+	}
+	return styles.Selected.Render(status)
+}
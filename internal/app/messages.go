@@ -3,6 +3,7 @@ package app
 import (
 	"context"
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
@@ -10,27 +11,62 @@ import (
 	"github.com/MonsieurTib/service-bus-tui/internal/styles"
 	"github.com/MonsieurTib/service-bus-tui/internal/table"
 	"github.com/charmbracelet/bubbles/spinner"
+	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/muesli/reflow/wordwrap"
 )
 
+const messagesPageSize = 100
+
 type MessagesModel struct {
-	client       *azure.ServiceBusClient
-	entityName   string // e.g. "topic/subscription" or "queue"
-	isDeadLetter bool
-	messages     []azure.MessageInfo
-	table        table.Model
-	spinner      spinner.Model
-	isLoading    bool
-	errMsg       string
-	width        int
-	height       int
-	isEmpty      bool
+	client        *azure.ServiceBusClient
+	entityName    string // e.g. "topic/subscription" or "queue"
+	isDeadLetter  bool
+	messages      []azure.MessageInfo
+	rows          []table.Row
+	table         table.Model
+	spinner       spinner.Model
+	isLoading     bool
+	isLoadingMore bool
+	hasMore       bool
+	nextSeq       int64         // sequence number the next page is peeked from
+	loadGen       int           // incremented by peekFrom, so pages peeked for the previous messages are dropped
+	group         *messageGroup // set when only the messages of a dead-letter group are shown
+	marked        map[int64]bool
+	action        *messageAction
+	picker        *EntityPickerModel
+	builder       *correlationBuilder
+	seekInput     textinput.Model
+	seekErr       string
+	filterInput   textinput.Model
+	filterErr     string
+	filter        *messageFilter
+	errMsg        string
+	width         int
+	height        int
+	isEmpty       bool
 }
 
+// MessagesLoadedMsg carries the first page of peeked messages for the given entity.
 type MessagesLoadedMsg struct {
-	Messages []azure.MessageInfo
+	EntityName         string
+	IsDeadLetter       bool
+	Messages           []azure.MessageInfo
+	FromSequenceNumber int64 // set when the peek was started from a user-entered sequence number
+	NextSequenceNumber int64
+	HasMore            bool
+	loadGen            int
+}
+
+// MoreMessagesLoadedMsg carries the next page of peeked messages for the given entity.
+type MoreMessagesLoadedMsg struct {
+	EntityName         string
+	IsDeadLetter       bool
+	Messages           []azure.MessageInfo
+	NextSequenceNumber int64
+	HasMore            bool
+	loadGen            int
 }
 
 func NewMessagesModel(client *azure.ServiceBusClient) *MessagesModel {
@@ -64,11 +100,23 @@ func NewMessagesModel(client *azure.ServiceBusClient) *MessagesModel {
 		Bold(false)
 	t.SetStyles(tableStyle)
 
+	ti := textinput.New()
+	ti.Prompt = "Seq#: "
+	ti.Placeholder = "sequence number"
+	ti.CharLimit = 20
+	ti.Width = 20
+
+	fi := textinput.New()
+	fi.Prompt = "Filter: "
+	fi.Placeholder = "SQL filter, e.g. sys.Label = 'order' AND quantity > 10 (empty to clear)"
+
 	return &MessagesModel{
-		client:  client,
-		spinner: s,
-		table:   t,
-		isEmpty: true,
+		client:      client,
+		spinner:     s,
+		table:       t,
+		seekInput:   ti,
+		filterInput: fi,
+		isEmpty:     true,
 	}
 }
 
@@ -82,23 +130,135 @@ func (m *MessagesModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
+		if m.picker != nil {
+			var cmd tea.Cmd
+			m.picker, cmd = m.picker.Update(msg)
+			return m, cmd
+		}
+		if m.builder != nil {
+			return m, m.updateCorrelationBuilder(msg)
+		}
+		if m.filterInput.Focused() {
+			return m.updateFilterInput(msg)
+		}
+		if m.isPrompting() {
+			return m.updateSeekInput(msg)
+		}
+		if m.action != nil {
+			return m, m.updateAction(msg)
+		}
 		if !m.isEmpty && !m.isLoading {
+			switch msg.String() {
+			case "n":
+				return m, m.loadMore()
+			case "s":
+				m.seekErr = ""
+				m.seekInput.SetValue("")
+				return m, m.seekInput.Focus()
+			case "x":
+				m.toggleMarked()
+				return m, nil
+			case "r":
+				return m, m.resubmit()
+			case "d":
+				return m, m.deleteMessages()
+			case "e":
+				return m, m.editAndResend()
+			case "t":
+				return m, m.openSendCopyPicker()
+			case "f":
+				return m, m.openFilterInput()
+			case "b":
+				return m, m.openCorrelationBuilder()
+			case "esc":
+				if m.filter != nil || m.filterErr != "" {
+					m.clearFilter()
+					return m, nil
+				}
+				if m.group != nil {
+					m.group = nil
+					return m, m.peekFrom(0)
+				}
+			}
 			var tableCmd tea.Cmd
 			m.table, tableCmd = m.table.Update(msg)
 			return m, tableCmd
 		}
 
 	case MessagesLoadedMsg:
+		if !m.isCurrentLoad(msg.EntityName, msg.IsDeadLetter, msg.loadGen) {
+			break
+		}
 		m.isLoading = false
 		m.messages = msg.Messages
+		m.hasMore = msg.HasMore
+		m.nextSeq = msg.NextSequenceNumber
 		m.updateTableRows()
+		switch {
+		case m.filter != nil:
+			m.applyFilter()
+		case msg.FromSequenceNumber > 0:
+			m.highlightSequenceNumber(msg.FromSequenceNumber)
+		}
+
+	case MoreMessagesLoadedMsg:
+		if !m.isCurrentLoad(msg.EntityName, msg.IsDeadLetter, msg.loadGen) {
+			break
+		}
+		m.isLoadingMore = false
+		m.hasMore = msg.HasMore
+		m.nextSeq = msg.NextSequenceNumber
+		m.appendMessages(msg.Messages)
+		m.applyFilter()
+
+	case MessageActionCompletedMsg:
+		if m.action != nil {
+			m.action.running = false
+			m.action.results = msg.Results
+			m.action.err = msg.Err
+		}
+
+	case resubmitCheckedMsg:
+		if cmd := m.handleResubmitChecked(msg); cmd != nil {
+			return m, cmd
+		}
+
+	case PickerEntitiesLoadedMsg:
+		if m.picker != nil {
+			var cmd tea.Cmd
+			m.picker, cmd = m.picker.Update(msg)
+			return m, cmd
+		}
+		// The picker was closed while connecting to another namespace: nobody else will close that client.
+		if msg.Client != nil && msg.Client != m.client {
+			return m, closeClientCmd(msg.Client)
+		}
+
+	case EntityPickedMsg:
+		if m.picker != nil {
+			m.picker = nil
+			return m, m.sendCopy(msg)
+		}
+
+	case MessageEditedMsg:
+		if cmd := m.handleMessageEdited(msg); cmd != nil {
+			return m, cmd
+		}
 
 	case ErrorMsg:
 		m.isLoading = false
+		m.isLoadingMore = false
+		m.action = nil
 		m.errMsg = string(msg)
 	}
 
-	if m.isLoading {
+	if m.picker != nil {
+		var cmd tea.Cmd
+		m.picker, cmd = m.picker.Update(msg)
+		return m, cmd
+	}
+
+	if m.isLoading || m.isLoadingMore || m.isActionRunning() {
 		return m, spinnerCmd
 	}
 
@@ -108,15 +268,107 @@ func (m *MessagesModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 func (m *MessagesModel) LoadMessages(entityName string, isDeadLetter bool) tea.Cmd {
 	m.entityName = entityName
 	m.isDeadLetter = isDeadLetter
+	m.group = nil
+	return m.peekFrom(0)
+}
+
+// LoadGroup shows only the messages of group in the dead-letter subqueue of entityName.
+func (m *MessagesModel) LoadGroup(entityName string, group messageGroup) tea.Cmd {
+	m.entityName = entityName
+	m.isDeadLetter = true
+	m.group = &group
+	return m.peekFrom(0)
+}
+
+// peekFrom replaces the loaded messages with a page starting at fromSequenceNumber. Pages still being peeked for
+// the previous messages, including a pending load more, are dropped when they arrive.
+func (m *MessagesModel) peekFrom(fromSequenceNumber int64) tea.Cmd {
+	m.loadGen++
 	m.isLoading = true
+	m.isLoadingMore = false
+	m.hasMore = false
 	m.isEmpty = false
 	m.errMsg = ""
 	m.messages = nil
+	m.marked = make(map[int64]bool)
 	m.updateTableRows()
+	m.table.SetHighlightedRows()
+	m.table.SetCursor(0)
 
 	return tea.Batch(
 		m.spinner.Tick,
-		m.loadMessagesCmd(),
+		m.loadMessagesCmd(fromSequenceNumber),
+	)
+}
+
+// isCurrentLoad reports whether a page was peeked for the messages currently shown, rather than for another entity
+// or for messages replaced since. Actions settle the shown messages by sequence number in m.entityName, so a page of
+// another entity must never be shown under it.
+func (m *MessagesModel) isCurrentLoad(entityName string, isDeadLetter bool, loadGen int) bool {
+	return entityName == m.entityName && isDeadLetter == m.isDeadLetter && loadGen == m.loadGen
+}
+
+// isPrompting reports whether keys are going to a text input, so the explorer should not handle them.
+func (m *MessagesModel) isPrompting() bool {
+	return m.seekInput.Focused() || m.filterInput.Focused() || m.picker != nil || m.builder != nil
+}
+
+// promptView renders the focused text input, with its error if any.
+func (m *MessagesModel) promptView() string {
+	input, errMsg := m.seekInput.View(), m.seekErr
+	if m.filterInput.Focused() {
+		input, errMsg = m.filterInput.View(), m.filterErr
+	}
+	if errMsg != "" {
+		return input + " " + styles.Error.Render(errMsg)
+	}
+	return input
+}
+
+func (m *MessagesModel) updateSeekInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.seekInput.Blur()
+		m.seekErr = ""
+		return m, nil
+	case "enter":
+		value := strings.TrimSpace(m.seekInput.Value())
+		seq, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || seq <= 0 {
+			m.seekErr = fmt.Sprintf("invalid sequence number: %q", value)
+			return m, nil
+		}
+		m.seekInput.Blur()
+		m.seekErr = ""
+		return m, m.peekFrom(seq)
+	}
+
+	var cmd tea.Cmd
+	m.seekInput, cmd = m.seekInput.Update(msg)
+	return m, cmd
+}
+
+// highlightSequenceNumber moves the cursor to the first message at or after seq and highlights it.
+func (m *MessagesModel) highlightSequenceNumber(seq int64) {
+	for i, msg := range m.messages {
+		if msg.SequenceNumber >= seq {
+			m.table.SetCursor(i)
+			m.table.SetHighlightedRows(i)
+			return
+		}
+	}
+}
+
+// loadMore peeks the page following the last loaded message.
+func (m *MessagesModel) loadMore() tea.Cmd {
+	if !m.hasMore || m.isLoadingMore {
+		return nil
+	}
+	m.isLoadingMore = true
+
+	return tea.Batch(
+		m.spinner.Tick,
+		m.loadMoreMessagesCmd(m.nextSeq),
 	)
 }
 
@@ -124,10 +376,15 @@ func (m *MessagesModel) SetSize(width, height int) {
 	m.width = width
 	m.height = height
 
+	// Reserve: table header (2) + status line (1) + extra (1)
 	tableHeight := max(height-4, 5)
 	m.table.SetHeight(tableHeight)
 
 	m.updateColumnWidths()
+
+	if m.picker != nil {
+		m.picker.SetSize(width, height)
+	}
 }
 
 func (m *MessagesModel) SetFocused(focused bool) {
@@ -157,11 +414,11 @@ func (m *MessagesModel) updateColumnWidths() {
 	available := m.width - 10
 
 	columns := []table.Column{
-		{Title: "Seq#", Width: min(8, available/5)},
+		{Title: "Seq#", Width: min(10, available/5)},
 		{Title: "Message ID", Width: min(24, available/5)},
 		{Title: "Subject", Width: min(20, available/5)},
 		{Title: "Enqueued", Width: min(20, available/5)},
-		{Title: "Body (preview)", Width: max(20, available-72)},
+		{Title: "Body (preview)", Width: max(20, available-74)},
 	}
 	m.table.SetColumns(columns)
 
@@ -171,21 +428,40 @@ func (m *MessagesModel) updateColumnWidths() {
 }
 
 func (m *MessagesModel) updateTableRows() {
-	var rows []table.Row
 	bodyColWidth := m.getBodyColumnWidth()
 
+	m.rows = make([]table.Row, 0, len(m.messages))
 	for _, msg := range m.messages {
-		bodyPreview := styles.FormatJSONCell([]byte(msg.Body), bodyColWidth)
+		m.rows = append(m.rows, messageRow(msg, m.marked[msg.SequenceNumber], bodyColWidth))
+	}
+	m.table.SetRows(m.rows)
+}
+
+// appendMessages adds a page of messages, rendering rows only for the new ones so
+// the table stays responsive as pages accumulate.
+func (m *MessagesModel) appendMessages(messages []azure.MessageInfo) {
+	bodyColWidth := m.getBodyColumnWidth()
 
-		rows = append(rows, table.Row{
-			fmt.Sprintf("%d", msg.SequenceNumber),
-			truncateString(msg.MessageID, 20),
-			truncateString(msg.Subject, 20),
-			msg.EnqueuedTime.Format("2006-01-02 15:04:05"),
-			bodyPreview,
-		})
+	m.messages = append(m.messages, messages...)
+	for _, msg := range messages {
+		m.rows = append(m.rows, messageRow(msg, false, bodyColWidth))
+	}
+	m.table.SetRows(m.rows)
+}
+
+func messageRow(msg azure.MessageInfo, marked bool, bodyColWidth int) table.Row {
+	seq := fmt.Sprintf("%d", msg.SequenceNumber)
+	if marked {
+		seq = "● " + seq
+	}
+
+	return table.Row{
+		seq,
+		truncateString(msg.MessageID, 20),
+		truncateString(msg.Subject, 20),
+		msg.EnqueuedTime.Format("2006-01-02 15:04:05"),
+		styles.FormatJSONCell([]byte(msg.Body), bodyColWidth),
 	}
-	m.table.SetRows(rows)
 }
 
 func (m *MessagesModel) getBodyColumnWidth() int {
@@ -193,7 +469,7 @@ func (m *MessagesModel) getBodyColumnWidth() int {
 		return 30
 	}
 	available := m.width - 10
-	return max(20, available-72)
+	return max(20, available-74)
 }
 
 func (m *MessagesModel) View() string {
@@ -214,28 +490,116 @@ func (m *MessagesModel) ViewContent() string {
 		return styles.Error.Render(wrapped)
 	}
 
+	if m.picker != nil {
+		return m.picker.View()
+	}
+	if m.builder != nil {
+		return m.viewCorrelationBuilder()
+	}
+
+	if m.action != nil {
+		return m.viewAction()
+	}
+
 	if len(m.messages) == 0 {
-		return styles.Subtle.Render("No messages found")
+		if m.isPrompting() {
+			return m.promptView()
+		}
+		if m.group != nil {
+			hint := "No messages of group " + m.group.String()
+			if m.hasMore {
+				hint += " yet • n: keep searching"
+			}
+			return styles.Subtle.Render(hint + " • esc: show all")
+		}
+		return styles.Subtle.Render("No messages found • s: seek to seq#")
 	}
 
-	return m.table.View()
+	return m.table.View() + "\n" + m.statusLine()
+}
+
+func (m *MessagesModel) statusLine() string {
+	if m.isPrompting() {
+		return m.promptView()
+	}
+
+	status := fmt.Sprintf("%d messages", len(m.messages))
+	if len(m.marked) > 0 {
+		status += fmt.Sprintf(" (%d marked)", len(m.marked))
+	}
+	switch {
+	case m.isLoadingMore:
+		return m.spinner.View() + " " + styles.Subtle.Render(status+" • loading more...")
+	case m.hasMore:
+		status += " • n: load more"
+	default:
+		status += " • end of entity"
+	}
+	status += " • s: seek to seq# • f: filter • x: mark • d: delete • e: edit and resend • t: send copy to • b: build rule"
+	if m.isDeadLetter {
+		status += " • r: resubmit"
+	}
+	if filter := m.filterStatus(); filter != "" {
+		return filter + " " + styles.Subtle.Render("(esc: clear) • "+status)
+	}
+	if m.group != nil {
+		return styles.Selected.Render("group: "+m.group.String()) + " " + styles.Subtle.Render("(esc: show all) • "+status)
+	}
+	return styles.Subtle.Render(status)
 }
 
-func (m *MessagesModel) loadMessagesCmd() tea.Cmd {
+func (m *MessagesModel) loadMessagesCmd(fromSequenceNumber int64) tea.Cmd {
 	client := m.client
 	entityName := m.entityName
 	isDeadLetter := m.isDeadLetter
+	group := m.group
+	loadGen := m.loadGen
 
 	return func() tea.Msg {
 		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 		defer cancel()
 
-		messages, err := client.PeekMessages(ctx, entityName, isDeadLetter, 100)
+		page, err := peekPage(ctx, client, entityName, isDeadLetter, fromSequenceNumber, group)
 		if err != nil {
 			return ErrorMsg(fmt.Sprintf("failed to peek messages: %v", err))
 		}
 
-		return MessagesLoadedMsg{Messages: messages}
+		return MessagesLoadedMsg{
+			EntityName:         entityName,
+			IsDeadLetter:       isDeadLetter,
+			Messages:           page.messages,
+			FromSequenceNumber: fromSequenceNumber,
+			NextSequenceNumber: page.next,
+			HasMore:            page.hasMore,
+			loadGen:            loadGen,
+		}
+	}
+}
+
+func (m *MessagesModel) loadMoreMessagesCmd(fromSequenceNumber int64) tea.Cmd {
+	client := m.client
+	entityName := m.entityName
+	isDeadLetter := m.isDeadLetter
+	group := m.group
+	loadGen := m.loadGen
+
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		page, err := peekPage(ctx, client, entityName, isDeadLetter, fromSequenceNumber, group)
+		if err != nil {
+			return ErrorMsg(fmt.Sprintf("failed to peek more messages: %v", err))
+		}
+
+		return MoreMessagesLoadedMsg{
+			EntityName:         entityName,
+			IsDeadLetter:       isDeadLetter,
+			Messages:           page.messages,
+			NextSequenceNumber: page.next,
+			HasMore:            page.hasMore,
+			loadGen:            loadGen,
+		}
 	}
 }
 
@@ -248,3 +612,53 @@ func truncateString(s string, maxLen int) string {
 	}
 	return s[:maxLen-3] + "..."
 }
+
+// maxGroupScanPages bounds the pages peeked to fill one page of a group, so a rare group doesn't run into the
+// context timeout. The rest is peeked with "load more".
+const maxGroupScanPages = 20
+
+type peekedPage struct {
+	messages []azure.MessageInfo
+	next     int64 // sequence number the following page starts from
+	hasMore  bool
+}
+
+// nextPeekPage returns the sequence number the page after messages starts from, and whether there may be one.
+// Peek can return fewer messages than asked while more remain, e.g. when large bodies reach the size cap of a call,
+// so only an empty page, or one that doesn't move past from, marks the end of the entity.
+func nextPeekPage(messages []azure.MessageInfo, from int64) (next int64, more bool) {
+	if len(messages) == 0 {
+		return from, false
+	}
+	next = messages[len(messages)-1].SequenceNumber + 1
+	return next, next > from
+}
+
+// peekPage peeks a page of messages from fromSequenceNumber. When group is set, only its messages are kept, and
+// pages are peeked until a page of them is found or the end of the entity is reached.
+func peekPage(ctx context.Context, client *azure.ServiceBusClient, entityName string, isDeadLetter bool, fromSequenceNumber int64, group *messageGroup) (peekedPage, error) {
+	page := peekedPage{next: fromSequenceNumber}
+
+	for range maxGroupScanPages {
+		messages, err := client.PeekMessages(ctx, entityName, isDeadLetter, page.next, messagesPageSize)
+		if err != nil {
+			return page, err
+		}
+		page.next, page.hasMore = nextPeekPage(messages, page.next)
+
+		if group == nil {
+			page.messages = messages
+			return page, nil
+		}
+		for _, msg := range messages {
+			if group.matches(msg) {
+				page.messages = append(page.messages, msg)
+			}
+		}
+		if !page.hasMore || len(page.messages) >= messagesPageSize {
+			break
+		}
+	}
+
+	return page, nil
+}
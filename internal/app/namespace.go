@@ -12,6 +12,7 @@ import (
 	"github.com/charmbracelet/bubbles/spinner"
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
 	"github.com/muesli/reflow/truncate"
 )
 
@@ -20,6 +21,9 @@ const (
 	NodeTypeQueue        = "queue"
 	NodeTypeSubscription = "subscription"
 	NodeTypeMessages     = "messages"
+	NodeTypeRules        = "rules"
+	NodeTypeRule         = "rule"
+	NodeTypeError        = "error" // why the children of its parent could not be loaded
 )
 
 type TreeNode struct {
@@ -31,7 +35,8 @@ type TreeNode struct {
 	IsLoading   bool
 	HasChildren bool
 	Depth       int
-	EntityName  string // e.g. "topic", "topic/subscription", or "queue"
+	EntityName  string          // e.g. "topic", "topic/subscription", or "queue"
+	Rule        *azure.RuleInfo // set on rule nodes
 }
 
 type NamespaceModel struct {
@@ -46,6 +51,13 @@ type NamespaceModel struct {
 	spinner           spinner.Model
 	viewport          viewport.Model
 	flatList          []*TreeNode
+	purge             *purgeState
+	counts            map[string]azure.MessageCounts
+	countsScheduled   bool
+	pendingNew        bool // "n" was pressed, waiting for the entity type
+	deleting          *deleteState
+	ruleDraft         *azure.RuleInfo // a rule waiting for the subscription to be picked in the tree
+	restore           *treeState
 }
 
 type TopicsAndQueuesLoadedMsg struct {
@@ -55,6 +67,7 @@ type TopicsAndQueuesLoadedMsg struct {
 type SubscriptionsLoadedMsg struct {
 	TopicID       string
 	Subscriptions []*TreeNode
+	Err           error
 }
 
 type MessagesSelectedMsg struct {
@@ -71,6 +84,7 @@ func NewNamespaceModel(namespace string, client *azure.ServiceBusClient) *Namesp
 		namespace:         namespace,
 		client:            client,
 		subscriptionCache: make(map[string][]*TreeNode),
+		counts:            make(map[string]azure.MessageCounts),
 		rootNodes:         []*TreeNode{},
 		selectedIdx:       0,
 		isLoading:         true,
@@ -93,17 +107,34 @@ func (n *NamespaceModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
+		if n.purge != nil {
+			return n, n.updatePurge(msg)
+		}
+		if n.deleting != nil {
+			return n, n.updateDelete(msg)
+		}
+		if n.pendingNew {
+			return n, n.updatePendingNew(msg)
+		}
+		if n.ruleDraft != nil {
+			if cmd, handled := n.updateRuleTarget(msg); handled {
+				return n, cmd
+			}
+		}
+
 		switch msg.String() {
 		case "up", "k":
 			if n.selectedIdx > 0 {
 				n.selectedIdx--
 			}
 			n.ensureSelectedVisible()
+			return n, n.focusSelectedEntity()
 		case "down", "j":
 			if n.selectedIdx < len(n.flatList)-1 {
 				n.selectedIdx++
 			}
 			n.ensureSelectedVisible()
+			return n, n.focusSelectedEntity()
 		case "right", "l", "enter":
 			if n.selectedIdx >= 0 && n.selectedIdx < len(n.flatList) {
 				node := n.flatList[n.selectedIdx]
@@ -125,6 +156,38 @@ func (n *NamespaceModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				n.collapseNode(node)
 				n.rebuildFlatList()
 			}
+		case "p":
+			if n.selectedIdx >= 0 && n.selectedIdx < len(n.flatList) {
+				n.startPurge(n.flatList[n.selectedIdx])
+			}
+		case "c":
+			if n.selectedIdx >= 0 && n.selectedIdx < len(n.flatList) {
+				node := n.flatList[n.selectedIdx]
+				if node.Type == NodeTypeTopic || node.Type == NodeTypeQueue {
+					return n, func() tea.Msg { return ComposeSelectedMsg{EntityName: node.EntityName} }
+				}
+			}
+		case "n":
+			n.pendingNew = true
+		case "e":
+			return n, n.editSelectedEntity()
+		case "d":
+			return n, n.startDelete()
+		case "g":
+			if node := n.selectedNode(); node != nil && node.Type == NodeTypeMessages && strings.HasSuffix(node.ID, "-dlq") {
+				req := DLQSummaryRequestedMsg{EntityName: node.EntityName}
+				return n, func() tea.Msg { return req }
+			}
+		case "a":
+			return n, func() tea.Msg { return SASPoliciesRequestedMsg{} }
+		case "f":
+			if n.selectedIdx >= 0 && n.selectedIdx < len(n.flatList) {
+				node := n.flatList[n.selectedIdx]
+				if node.Type == NodeTypeRule && node.Rule != nil {
+					rule := *node.Rule
+					return n, func() tea.Msg { return TestRuleMsg{Rule: rule} }
+				}
+			}
 		}
 
 	case tea.WindowSizeMsg:
@@ -139,7 +202,26 @@ func (n *NamespaceModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		n.rebuildFlatList()
 		n.viewport.YOffset = 0
 
+		cmds := []tea.Cmd{n.restoreTreeState(n.rootNodes, ""), n.loadCountsCmd(true, nil), n.focusSelectedEntity()}
+		if !n.countsScheduled {
+			n.countsScheduled = true
+			cmds = append(cmds, scheduleCountsRefresh(n.client))
+		}
+		return n, tea.Batch(cmds...)
+
 	case SubscriptionsLoadedMsg:
+		if msg.Err != nil {
+			n.errMsg = msg.Err.Error()
+			if node := n.findNodeByID(msg.TopicID); node != nil {
+				// Collapsed so expanding it again retries.
+				node.IsLoading = false
+				node.IsExpanded = false
+			}
+			n.rebuildFlatList()
+			// Settles the load a refresh may be waiting for, with no subscriptions to re-expand.
+			return n, n.restoreTreeState(nil, msg.TopicID)
+		}
+
 		n.cacheMutex.Lock()
 		n.subscriptionCache[msg.TopicID] = msg.Subscriptions
 		n.cacheMutex.Unlock()
@@ -153,11 +235,72 @@ func (n *NamespaceModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		n.rebuildFlatList()
 
+		topicName := strings.TrimPrefix(msg.TopicID, "topic-")
+		return n, tea.Batch(spinnerCmd, n.restoreTreeState(msg.Subscriptions, msg.TopicID), n.loadCountsCmd(false, []string{topicName}))
+
+	case RulesLoadedMsg:
+		return n, tea.Batch(spinnerCmd, n.handleRulesLoaded(msg))
+
+	case refreshCountsMsg:
+		if msg.client != n.client {
+			return n, nil
+		}
+		return n, tea.Batch(
+			n.loadCountsCmd(true, n.loadedTopics()),
+			scheduleCountsRefresh(n.client),
+		)
+
+	case entityFocusMsg:
+		if node := n.selectedNode(); node != nil && node.ID == msg.nodeID {
+			return n, n.loadPropertiesCmd(node)
+		}
+
+	case CountsLoadedMsg:
+		if msg.client != n.client {
+			return n, nil
+		}
+		for name, c := range msg.Counts {
+			n.counts[name] = c
+		}
+
+	case EntityDeletedMsg:
+		if n.deleting == nil {
+			break
+		}
+		n.deleting.running = false
+		if msg.Err != nil {
+			n.deleting.err = msg.Err
+			break
+		}
+		n.deleting = nil
+		return n, n.refresh()
+
+	case PurgeProgressMsg:
+		if n.isPurgeRunning() {
+			n.purge.purged = msg.Purged
+			return n, tea.Batch(spinnerCmd, waitForPurgeProgress(n.purge.progress))
+		}
+
+	case PurgeCompletedMsg:
+		if n.isPurgeRunning() {
+			n.purge.cancel()
+			n.purge.running = false
+			n.purge.purged = msg.Purged
+			n.purge.err = msg.Err
+		}
+		return n, n.loadCountsCmd(true, n.loadedTopics())
+
 	case ErrorMsg:
 		n.errMsg = string(msg)
 	}
 
-	if n.isLoading || n.anyNodeLoading() {
+	if n.deleting != nil && !n.deleting.running {
+		var cmd tea.Cmd
+		n.deleting.input, cmd = n.deleting.input.Update(msg)
+		return n, cmd
+	}
+
+	if n.isLoading || n.anyNodeLoading() || n.isPurgeRunning() || n.deleting != nil {
 		return n, spinnerCmd
 	}
 
@@ -215,7 +358,7 @@ func (n *NamespaceModel) View() string {
 		s.WriteString(n.ViewContent())
 
 		s.WriteString("\n")
-		s.WriteString(styles.Subtle.Render("↑↓/jk: navigate • →/l/enter: expand • ←/h: collapse • ctrl+c: quit"))
+		s.WriteString(styles.Subtle.Render("↑↓/jk: navigate • →/l/enter: expand • ←/h: collapse • c: compose • n: new • e: edit • d: delete • f: test rule • g: DLQ summary • p: purge • a: SAS policies • ctrl+c: quit"))
 		s.WriteString("\n")
 	}
 
@@ -237,6 +380,16 @@ func (n *NamespaceModel) ViewContent() string {
 		return s.String()
 	}
 
+	if n.purge != nil {
+		return n.viewPurge()
+	}
+	if n.deleting != nil {
+		return n.viewDelete()
+	}
+	if n.pendingNew {
+		return n.viewPendingNew()
+	}
+
 	if len(n.flatList) == 0 {
 		s.WriteString(styles.Subtle.Render("No topics or queues found"))
 		return s.String()
@@ -271,7 +424,11 @@ func (n *NamespaceModel) drawNodeLine(s *strings.Builder, node *TreeNode, isSele
 			icon = "›"
 		}
 	case NodeTypeQueue:
-		icon = "□"
+		if node.IsExpanded {
+			icon = "■"
+		} else {
+			icon = "□"
+		}
 	case NodeTypeSubscription:
 		if node.IsExpanded {
 			icon = "⌄"
@@ -280,12 +437,22 @@ func (n *NamespaceModel) drawNodeLine(s *strings.Builder, node *TreeNode, isSele
 		}
 	case NodeTypeMessages:
 		icon = "◉"
+	case NodeTypeRules:
+		if node.IsExpanded {
+			icon = "⌄"
+		} else {
+			icon = "›"
+		}
+	case NodeTypeRule:
+		icon = "ƒ"
+	case NodeTypeError:
+		icon = "✗"
 	default:
 		icon = "○"
 	}
 
 	var display string
-	if node.Type == NodeTypeSubscription || node.Type == NodeTypeMessages {
+	if node.Type == NodeTypeSubscription || node.Type == NodeTypeMessages || node.Type == NodeTypeRules || node.Type == NodeTypeRule || node.Type == NodeTypeError {
 		display = fmt.Sprintf("%s  %s %s", indent, icon, node.Name)
 	} else {
 		display = fmt.Sprintf("%s%s %s", indent, icon, node.Name)
@@ -297,16 +464,28 @@ func (n *NamespaceModel) drawNodeLine(s *strings.Builder, node *TreeNode, isSele
 
 	linePrefix := "  "
 
-	if maxWidth := n.viewport.Width - len(linePrefix); maxWidth > 0 {
+	// Badges stay visible; the name is truncated to make room for them.
+	badges := n.countBadges(node)
+	maxWidth := n.viewport.Width - len(linePrefix)
+	if badges != "" {
+		maxWidth -= lipgloss.Width(badges) + 1
+	}
+	if maxWidth > 0 {
 		display = truncate.StringWithTail(display, uint(maxWidth), "…")
 	}
 
 	var line string
-	if isSelected {
+	switch {
+	case isSelected:
 		line = linePrefix + styles.Selected.Render(display)
-	} else {
+	case node.Type == NodeTypeError:
+		line = linePrefix + styles.Error.Render(display)
+	default:
 		line = linePrefix + display
 	}
+	if badges != "" {
+		line += " " + badges
+	}
 
 	s.WriteString(line)
 	s.WriteString("\n")
@@ -381,6 +560,12 @@ func (n *NamespaceModel) handleExpandNode(node *TreeNode) tea.Cmd {
 		return n.loadSubscriptionsCmd(node.ID)
 	}
 
+	// Rules are loaded again after an error, e.g. once the missing permission has been granted.
+	if node.Type == NodeTypeRules && (len(node.Children) == 0 || node.Children[0].Type == NodeTypeError) {
+		node.IsLoading = true
+		return n.loadRulesCmd(node)
+	}
+
 	return nil
 }
 
@@ -434,6 +619,7 @@ func (n *NamespaceModel) loadTopicsAndQueuesCmd() tea.Cmd {
 				ID:          fmt.Sprintf("topic-%s", topic),
 				Name:        topic,
 				Type:        NodeTypeTopic,
+				EntityName:  topic,
 				HasChildren: true,
 				Children:    []*TreeNode{},
 				Depth:       0,
@@ -445,8 +631,9 @@ func (n *NamespaceModel) loadTopicsAndQueuesCmd() tea.Cmd {
 				ID:          fmt.Sprintf("queue-%s", queue),
 				Name:        queue,
 				Type:        NodeTypeQueue,
-				HasChildren: false,
-				Children:    []*TreeNode{},
+				EntityName:  queue,
+				HasChildren: true,
+				Children:    newMessagesNodes(fmt.Sprintf("queue-%s", queue), queue, 1),
 				Depth:       0,
 			})
 		}
@@ -466,7 +653,7 @@ func (n *NamespaceModel) loadSubscriptionsCmd(topicID string) tea.Cmd {
 
 		subscriptions, err := client.ListSubscriptions(ctx, topicName)
 		if err != nil {
-			return ErrorMsg(fmt.Sprintf("failed to load subscriptions for %s: %v", topicName, err))
+			return SubscriptionsLoadedMsg{TopicID: topicID, Err: fmt.Errorf("failed to load subscriptions for %s: %w", topicName, err)}
 		}
 
 		var nodes []*TreeNode
@@ -478,27 +665,8 @@ func (n *NamespaceModel) loadSubscriptionsCmd(topicID string) tea.Cmd {
 				Type:        NodeTypeSubscription,
 				EntityName:  entityName,
 				HasChildren: true,
-				Children: []*TreeNode{
-					{
-						ID:          fmt.Sprintf("sub-%s-%s-active", topicName, sub),
-						Name:        "Active Messages",
-						Type:        NodeTypeMessages,
-						EntityName:  entityName,
-						HasChildren: false,
-						Children:    []*TreeNode{},
-						Depth:       2,
-					},
-					{
-						ID:          fmt.Sprintf("sub-%s-%s-dlq", topicName, sub),
-						Name:        "DLQ Messages",
-						Type:        NodeTypeMessages,
-						EntityName:  entityName,
-						HasChildren: false,
-						Children:    []*TreeNode{},
-						Depth:       2,
-					},
-				},
-				Depth: 1,
+				Children:    newSubscriptionChildren(fmt.Sprintf("sub-%s-%s", topicName, sub), entityName),
+				Depth:       1,
 			}
 			nodes = append(nodes, subNode)
 		}
@@ -510,4 +678,41 @@ func (n *NamespaceModel) loadSubscriptionsCmd(topicID string) tea.Cmd {
 	}
 }
 
+// newMessagesNodes returns the "Active Messages" and "DLQ Messages" children shared by queues and subscriptions.
+func newMessagesNodes(idPrefix, entityName string, depth int) []*TreeNode {
+	return []*TreeNode{
+		{
+			ID:          idPrefix + "-active",
+			Name:        "Active Messages",
+			Type:        NodeTypeMessages,
+			EntityName:  entityName,
+			HasChildren: false,
+			Children:    []*TreeNode{},
+			Depth:       depth,
+		},
+		{
+			ID:          idPrefix + "-dlq",
+			Name:        "DLQ Messages",
+			Type:        NodeTypeMessages,
+			EntityName:  entityName,
+			HasChildren: false,
+			Children:    []*TreeNode{},
+			Depth:       depth,
+		},
+	}
+}
+
+// newSubscriptionChildren adds the "Rules" node to the messages nodes of a subscription.
+func newSubscriptionChildren(idPrefix, entityName string) []*TreeNode {
+	return append(newMessagesNodes(idPrefix, entityName, 2), &TreeNode{
+		ID:          idPrefix + "-rules",
+		Name:        "Rules",
+		Type:        NodeTypeRules,
+		EntityName:  entityName,
+		HasChildren: true,
+		Children:    []*TreeNode{},
+		Depth:       2,
+	})
+}
+
 const defaultContextTimeout = 30 * time.Second
@@ -0,0 +1,88 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/MonsieurTib/service-bus-tui/internal/azure"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// propertiesDelay debounces properties requests while the cursor moves through the tree.
+const propertiesDelay = 300 * time.Millisecond
+
+type entityFocusMsg struct {
+	nodeID string
+}
+
+// EntityPropertiesLoadedMsg carries the properties of the tree node NodeID.
+type EntityPropertiesLoadedMsg struct {
+	NodeID     string
+	EntityName string
+	Properties *azure.EntityProperties
+	Err        error
+}
+
+func (n *NamespaceModel) selectedNode() *TreeNode {
+	if n.selectedIdx >= 0 && n.selectedIdx < len(n.flatList) {
+		return n.flatList[n.selectedIdx]
+	}
+	return nil
+}
+
+// focusSelectedEntity loads the properties of the selected topic, queue or subscription once the cursor has
+// rested on it for propertiesDelay. Rules are already loaded, so they are shown straight away.
+func (n *NamespaceModel) focusSelectedEntity() tea.Cmd {
+	node := n.selectedNode()
+	if node == nil || node.EntityName == "" {
+		return nil
+	}
+
+	switch node.Type {
+	case NodeTypeRule:
+		msg := RuleFocusedMsg{EntityName: node.EntityName, Rule: *node.Rule}
+		return func() tea.Msg { return msg }
+	case NodeTypeTopic, NodeTypeQueue, NodeTypeSubscription:
+	default:
+		return nil
+	}
+
+	nodeID := node.ID
+	return tea.Tick(propertiesDelay, func(time.Time) tea.Msg {
+		return entityFocusMsg{nodeID: nodeID}
+	})
+}
+
+func (n *NamespaceModel) loadPropertiesCmd(node *TreeNode) tea.Cmd {
+	client := n.client
+	nodeID := node.ID
+	entityName := node.EntityName
+	nodeType := node.Type
+
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), defaultContextTimeout)
+		defer cancel()
+
+		props, err := getEntityProperties(ctx, client, nodeType, entityName)
+		return EntityPropertiesLoadedMsg{
+			NodeID:     nodeID,
+			EntityName: entityName,
+			Properties: props,
+			Err:        err,
+		}
+	}
+}
+
+// getEntityProperties fetches the properties of a topic, queue or subscription node.
+func getEntityProperties(ctx context.Context, client *azure.ServiceBusClient, nodeType, entityName string) (*azure.EntityProperties, error) {
+	switch nodeType {
+	case NodeTypeQueue:
+		return client.GetQueueProperties(ctx, entityName)
+	case NodeTypeTopic:
+		return client.GetTopicProperties(ctx, entityName)
+	case NodeTypeSubscription:
+		return client.GetSubscriptionProperties(ctx, entityName)
+	}
+	return nil, fmt.Errorf("%s has no properties", entityName)
+}
@@ -0,0 +1,176 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/MonsieurTib/service-bus-tui/internal/styles"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/muesli/reflow/wordwrap"
+)
+
+// purgeState tracks a purge of a queue, subscription or dead-letter subqueue started from the tree.
+type purgeState struct {
+	entityName   string
+	isDeadLetter bool
+	confirming   bool
+	running      bool
+	purged       int
+	err          error
+	cancel       context.CancelFunc
+	progress     chan int
+}
+
+// PurgeProgressMsg reports the number of messages deleted so far by the running purge.
+type PurgeProgressMsg struct {
+	Purged int
+}
+
+type PurgeCompletedMsg struct {
+	EntityName   string
+	IsDeadLetter bool
+	Purged       int
+	Err          error
+}
+
+// purgeTarget returns the entity a purge of node applies to. Queue and subscription nodes purge their active
+// messages; messages nodes purge the subqueue they show.
+func purgeTarget(node *TreeNode) (entityName string, isDeadLetter bool, ok bool) {
+	switch node.Type {
+	case NodeTypeQueue, NodeTypeSubscription:
+		return node.EntityName, false, node.EntityName != ""
+	case NodeTypeMessages:
+		return node.EntityName, strings.HasSuffix(node.ID, "-dlq"), node.EntityName != ""
+	}
+	return "", false, false
+}
+
+// startPurge asks for confirmation before purging the entity behind node.
+func (n *NamespaceModel) startPurge(node *TreeNode) {
+	entityName, isDeadLetter, ok := purgeTarget(node)
+	if !ok {
+		return
+	}
+
+	n.purge = &purgeState{
+		entityName:   entityName,
+		isDeadLetter: isDeadLetter,
+		confirming:   true,
+	}
+}
+
+func (n *NamespaceModel) isPurgeRunning() bool {
+	return n.purge != nil && n.purge.running
+}
+
+func (n *NamespaceModel) updatePurge(msg tea.KeyMsg) tea.Cmd {
+	p := n.purge
+
+	switch {
+	case p.confirming:
+		switch msg.String() {
+		case "y":
+			p.confirming = false
+			p.running = true
+
+			ctx, cancel := context.WithCancel(context.Background())
+			p.cancel = cancel
+			p.progress = make(chan int, 1)
+			return tea.Batch(
+				n.spinner.Tick,
+				n.purgeCmd(ctx, p.entityName, p.isDeadLetter, p.progress),
+				waitForPurgeProgress(p.progress),
+			)
+		case "n", "esc":
+			n.purge = nil
+		}
+
+	case p.running:
+		if msg.String() == "esc" {
+			p.cancel()
+		}
+
+	default:
+		switch msg.String() {
+		case "enter", "esc":
+			n.purge = nil
+		}
+	}
+
+	return nil
+}
+
+func (n *NamespaceModel) viewPurge() string {
+	p := n.purge
+	width := max(n.viewport.Width-2, 10)
+
+	source := p.entityName
+	if p.isDeadLetter {
+		source += " (DLQ)"
+	}
+
+	var s strings.Builder
+
+	switch {
+	case p.confirming:
+		s.WriteString(styles.Error.Render(wordwrap.String(fmt.Sprintf("Purge all messages from %s?", source), width)))
+		s.WriteString("\n\n")
+		s.WriteString(styles.Subtle.Render("y: confirm • n/esc: cancel"))
+
+	case p.running:
+		s.WriteString(n.spinner.View())
+		s.WriteString(" ")
+		s.WriteString(wordwrap.String(fmt.Sprintf("Purging %s: %d messages deleted", source, p.purged), width))
+		s.WriteString("\n\n")
+		s.WriteString(styles.Subtle.Render("esc: cancel"))
+
+	default:
+		switch {
+		case errors.Is(p.err, context.Canceled):
+			s.WriteString(wordwrap.String(fmt.Sprintf("Purge of %s cancelled after %d messages", source, p.purged), width))
+		case p.err != nil:
+			s.WriteString(styles.Error.Render(wordwrap.String(fmt.Sprintf("Purge of %s failed after %d messages: %v", source, p.purged, p.err), width)))
+		default:
+			s.WriteString(styles.Selected.Render(wordwrap.String(fmt.Sprintf("Purged %d messages from %s", p.purged, source), width)))
+		}
+		s.WriteString("\n\n")
+		s.WriteString(styles.Subtle.Render("enter/esc: close"))
+	}
+
+	return s.String()
+}
+
+func (n *NamespaceModel) purgeCmd(ctx context.Context, entityName string, isDeadLetter bool, progress chan int) tea.Cmd {
+	client := n.client
+
+	return func() tea.Msg {
+		defer close(progress)
+
+		purged, err := client.PurgeMessages(ctx, entityName, isDeadLetter, func(purged int) {
+			// Skip the update if the previous one has not been rendered yet.
+			select {
+			case progress <- purged:
+			default:
+			}
+		})
+
+		return PurgeCompletedMsg{
+			EntityName:   entityName,
+			IsDeadLetter: isDeadLetter,
+			Purged:       purged,
+			Err:          err,
+		}
+	}
+}
+
+func waitForPurgeProgress(progress chan int) tea.Cmd {
+	return func() tea.Msg {
+		purged, ok := <-progress
+		if !ok {
+			return nil
+		}
+		return PurgeProgressMsg{Purged: purged}
+	}
+}
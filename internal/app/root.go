@@ -1,6 +1,11 @@
 package app
 
 import (
+	"context"
+	"log"
+
+	"github.com/MonsieurTib/service-bus-tui/internal/azure"
+	"github.com/MonsieurTib/service-bus-tui/internal/config"
 	tea "github.com/charmbracelet/bubbletea"
 )
 
@@ -19,10 +24,11 @@ type RootModel struct {
 	windowHeight  int
 }
 
-func NewRootModel() *RootModel {
+// NewRootModel starts at the authentication screen, which connects straight to profile when it is not nil.
+func NewRootModel(profile *config.Profile) *RootModel {
 	return &RootModel{
 		state:     StateAuth,
-		authModel: NewAuthModel(),
+		authModel: NewAuthModel(profile),
 	}
 }
 
@@ -38,9 +44,14 @@ func (m *RootModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	switch msg := msg.(type) {
 	case NamespaceConnectedMsg:
+		var closeCmd tea.Cmd
+		if m.explorerModel != nil {
+			// Reconnecting, e.g. with a SAS policy: the previous connection is no longer used.
+			closeCmd = closeClientCmd(m.explorerModel.client)
+		}
 		m.explorerModel = NewExplorerModel(msg.Namespace, msg.Client)
 		m.state = StateExplorer
-		initCmd := m.explorerModel.Init()
+		initCmd := tea.Batch(closeCmd, m.explorerModel.Init())
 		if m.windowWidth > 0 && m.windowHeight > 0 {
 			wsMsg := tea.WindowSizeMsg{Width: m.windowWidth, Height: m.windowHeight}
 			_, sizeCmd := m.explorerModel.Update(wsMsg)
@@ -82,3 +93,15 @@ func (m *RootModel) View() string {
 	}
 	return ""
 }
+
+func closeClientCmd(client *azure.ServiceBusClient) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), defaultContextTimeout)
+		defer cancel()
+
+		if err := client.Close(ctx); err != nil {
+			log.Printf("failed to close connection to %s: %v", client.GetNamespace(), err)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,370 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/MonsieurTib/service-bus-tui/internal/azure"
+	"github.com/MonsieurTib/service-bus-tui/internal/styles"
+	"github.com/charmbracelet/bubbles/spinner"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/muesli/reflow/wordwrap"
+)
+
+const (
+	ruleFieldName = iota
+	ruleFieldFilterType
+	ruleFieldSQLExpression
+	ruleFieldCorrelationID
+	ruleFieldMessageID
+	ruleFieldTo
+	ruleFieldReplyTo
+	ruleFieldSubject
+	ruleFieldSessionID
+	ruleFieldReplyToSessionID
+	ruleFieldContentType
+	ruleFieldProperties
+	ruleFieldAction
+	ruleFieldCount
+)
+
+var ruleFieldLabels = [ruleFieldCount]string{
+	ruleFieldName:             "Name",
+	ruleFieldFilterType:       "Filter",
+	ruleFieldSQLExpression:    "SQL filter",
+	ruleFieldCorrelationID:    "Correlation ID",
+	ruleFieldMessageID:        "Message ID",
+	ruleFieldTo:               "To",
+	ruleFieldReplyTo:          "Reply to",
+	ruleFieldSubject:          "Subject",
+	ruleFieldSessionID:        "Session ID",
+	ruleFieldReplyToSessionID: "Reply session",
+	ruleFieldContentType:      "Content-Type",
+	ruleFieldProperties:       "Properties",
+	ruleFieldAction:           "SQL action",
+}
+
+var ruleFieldPlaceholders = [ruleFieldCount]string{
+	ruleFieldFilterType:    "SQL, Correlation, True or False",
+	ruleFieldSQLExpression: "e.g. color = 'red' AND quantity > 10",
+	ruleFieldProperties:    "key=value; other=42",
+	ruleFieldAction:        "optional, e.g. SET priority = 'high'",
+}
+
+var ruleFilterTypes = []string{azure.RuleFilterSQL, azure.RuleFilterCorrelation, azure.RuleFilterTrue, azure.RuleFilterFalse}
+
+// RuleFormModel creates or edits a subscription rule. Only the fields of the chosen filter type are shown.
+type RuleFormModel struct {
+	client     *azure.ServiceBusClient
+	entityName string          // "topic/subscription"
+	rule       *azure.RuleInfo // the rule being edited, nil when creating one
+	inputs     [ruleFieldCount]textinput.Model
+	properties map[string]any // the typed correlation properties the form was filled with
+	propsText  string         // how they were written in the properties field
+	focus      int            // one of the ruleField* constants
+	spinner    spinner.Model
+	isSaving   bool
+	errMsg     string
+	width      int
+	height     int
+}
+
+// RuleFormRequestedMsg opens the rule form for the subscription EntityName. Rule is nil to create a new rule,
+// pre-filled from Draft when it is set.
+type RuleFormRequestedMsg struct {
+	EntityName string
+	Rule       *azure.RuleInfo
+	Draft      *azure.RuleInfo
+}
+
+type RuleFormClosedMsg struct{}
+
+type RuleSavedMsg struct {
+	EntityName string
+	Err        error
+}
+
+func NewRuleFormModel(client *azure.ServiceBusClient, req RuleFormRequestedMsg) *RuleFormModel {
+	s := spinner.New()
+	s.Spinner = spinner.MiniDot
+
+	m := &RuleFormModel{
+		client:     client,
+		entityName: req.EntityName,
+		rule:       req.Rule,
+		spinner:    s,
+	}
+
+	for i := range m.inputs {
+		ti := textinput.New()
+		ti.Prompt = ""
+		ti.Placeholder = ruleFieldPlaceholders[i]
+		m.inputs[i] = ti
+	}
+
+	r := req.Rule
+	if r == nil {
+		r = req.Draft
+	}
+	if r != nil {
+		c := r.Correlation
+		m.inputs[ruleFieldFilterType].SetValue(r.FilterType)
+		m.inputs[ruleFieldSQLExpression].SetValue(r.SQLExpression)
+		m.inputs[ruleFieldCorrelationID].SetValue(c.CorrelationID)
+		m.inputs[ruleFieldMessageID].SetValue(c.MessageID)
+		m.inputs[ruleFieldTo].SetValue(c.To)
+		m.inputs[ruleFieldReplyTo].SetValue(c.ReplyTo)
+		m.inputs[ruleFieldSubject].SetValue(c.Subject)
+		m.inputs[ruleFieldSessionID].SetValue(c.SessionID)
+		m.inputs[ruleFieldReplyToSessionID].SetValue(c.ReplyToSessionID)
+		m.inputs[ruleFieldContentType].SetValue(c.ContentType)
+		if len(c.Properties) > 0 {
+			m.properties = c.Properties
+			m.propsText = formatProperties(c.Properties)
+			m.inputs[ruleFieldProperties].SetValue(m.propsText)
+		}
+		m.inputs[ruleFieldAction].SetValue(r.ActionExpression)
+	} else {
+		m.inputs[ruleFieldFilterType].SetValue(azure.RuleFilterSQL)
+	}
+	if req.Rule != nil {
+		// The name of an existing rule can't be changed.
+		m.focus = ruleFieldFilterType
+	}
+	m.inputs[m.focus].Focus()
+
+	return m
+}
+
+func (m *RuleFormModel) Init() tea.Cmd {
+	return textinput.Blink
+}
+
+func (m *RuleFormModel) Update(msg tea.Msg) (*RuleFormModel, tea.Cmd) {
+	var spinnerCmd tea.Cmd
+	m.spinner, spinnerCmd = m.spinner.Update(msg)
+
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		if msg.String() == "esc" {
+			return m, func() tea.Msg { return RuleFormClosedMsg{} }
+		}
+		if m.isSaving {
+			return m, nil
+		}
+
+		switch msg.String() {
+		case "tab", "down", "enter":
+			return m, m.moveFocus(1)
+		case "shift+tab", "up":
+			return m, m.moveFocus(-1)
+		case "ctrl+s":
+			return m, m.save()
+		}
+
+		var cmd tea.Cmd
+		m.inputs[m.focus], cmd = m.inputs[m.focus].Update(msg)
+		return m, cmd
+
+	case RuleSavedMsg:
+		m.isSaving = false
+		if msg.Err != nil {
+			m.errMsg = msg.Err.Error()
+		}
+	}
+
+	if m.isSaving {
+		return m, spinnerCmd
+	}
+
+	var cmd tea.Cmd
+	m.inputs[m.focus], cmd = m.inputs[m.focus].Update(msg)
+	return m, cmd
+}
+
+// filterType returns the filter type typed in the form, matched case-insensitively, or "" when it is not valid.
+func (m *RuleFormModel) filterType() string {
+	value := strings.TrimSpace(m.inputs[ruleFieldFilterType].Value())
+	i := slices.IndexFunc(ruleFilterTypes, func(t string) bool { return strings.EqualFold(t, value) })
+	if i < 0 {
+		return ""
+	}
+	return ruleFilterTypes[i]
+}
+
+// visibleFields returns the fields that apply to the filter type. The name can't be changed once created.
+func (m *RuleFormModel) visibleFields() []int {
+	var fields []int
+	if m.rule == nil {
+		fields = append(fields, ruleFieldName)
+	}
+	fields = append(fields, ruleFieldFilterType)
+
+	switch m.filterType() {
+	case azure.RuleFilterSQL:
+		fields = append(fields, ruleFieldSQLExpression)
+	case azure.RuleFilterCorrelation:
+		for f := ruleFieldCorrelationID; f <= ruleFieldProperties; f++ {
+			fields = append(fields, f)
+		}
+	}
+
+	return append(fields, ruleFieldAction)
+}
+
+func (m *RuleFormModel) moveFocus(delta int) tea.Cmd {
+	fields := m.visibleFields()
+	i := slices.Index(fields, m.focus)
+
+	m.inputs[m.focus].Blur()
+	m.focus = fields[(i+delta+len(fields))%len(fields)]
+	return m.inputs[m.focus].Focus()
+}
+
+func (m *RuleFormModel) SetSize(width, height int) {
+	m.width = width
+	m.height = height
+
+	for i := range m.inputs {
+		m.inputs[i].Width = max(width-17, 10)
+	}
+}
+
+func (m *RuleFormModel) value(field int) string {
+	return strings.TrimSpace(m.inputs[field].Value())
+}
+
+func (m *RuleFormModel) buildRule() (azure.RuleInfo, error) {
+	var rule azure.RuleInfo
+	if m.rule != nil {
+		// Keep the SQL parameters, which the form doesn't edit.
+		rule = azure.RuleInfo{
+			Name:             m.rule.Name,
+			SQLParameters:    m.rule.SQLParameters,
+			ActionParameters: m.rule.ActionParameters,
+		}
+	} else {
+		rule.Name = m.value(ruleFieldName)
+		if rule.Name == "" {
+			return rule, fmt.Errorf("name cannot be empty")
+		}
+	}
+
+	rule.FilterType = m.filterType()
+	switch rule.FilterType {
+	case "":
+		return rule, fmt.Errorf("invalid filter type %q, expected one of %s", m.value(ruleFieldFilterType), strings.Join(ruleFilterTypes, ", "))
+
+	case azure.RuleFilterSQL:
+		rule.SQLExpression = m.value(ruleFieldSQLExpression)
+		if rule.SQLExpression == "" {
+			return rule, fmt.Errorf("SQL filter cannot be empty")
+		}
+
+	case azure.RuleFilterCorrelation:
+		// Properties the user didn't touch are kept as they were, with their types.
+		props := maps.Clone(m.properties)
+		if text := m.value(ruleFieldProperties); text != m.propsText {
+			var err error
+			if props, err = parseProperties(text); err != nil {
+				return rule, err
+			}
+		}
+		rule.Correlation = azure.CorrelationFilter{
+			CorrelationID:    m.value(ruleFieldCorrelationID),
+			MessageID:        m.value(ruleFieldMessageID),
+			To:               m.value(ruleFieldTo),
+			ReplyTo:          m.value(ruleFieldReplyTo),
+			Subject:          m.value(ruleFieldSubject),
+			SessionID:        m.value(ruleFieldSessionID),
+			ReplyToSessionID: m.value(ruleFieldReplyToSessionID),
+			ContentType:      m.value(ruleFieldContentType),
+			Properties:       props,
+		}
+		if rule.Correlation.IsEmpty() {
+			return rule, fmt.Errorf("correlation filter needs at least one field")
+		}
+	}
+
+	rule.ActionExpression = m.value(ruleFieldAction)
+	if rule.ActionExpression == "" {
+		rule.ActionParameters = nil
+	}
+
+	return rule, nil
+}
+
+func (m *RuleFormModel) save() tea.Cmd {
+	rule, err := m.buildRule()
+	if err != nil {
+		m.errMsg = err.Error()
+		return nil
+	}
+
+	m.errMsg = ""
+	m.isSaving = true
+	return tea.Batch(m.spinner.Tick, m.saveCmd(rule))
+}
+
+func (m *RuleFormModel) View() string {
+	var s strings.Builder
+
+	title := "New rule in " + m.entityName
+	if m.rule != nil {
+		title = fmt.Sprintf("Edit rule %s in %s", m.rule.Name, m.entityName)
+	}
+	s.WriteString(detailHeaderStyle.Render(title))
+	s.WriteString("\n")
+	s.WriteString(detailSeparator)
+	s.WriteString("\n\n")
+
+	for _, field := range m.visibleFields() {
+		label := fmt.Sprintf("%-15s", ruleFieldLabels[field]+":")
+		if field == m.focus {
+			s.WriteString(styles.Label.Render(label))
+		} else {
+			s.WriteString(detailLabelStyle.Render(label))
+		}
+		s.WriteString(" ")
+		s.WriteString(m.inputs[field].View())
+		s.WriteString("\n")
+	}
+
+	s.WriteString("\n")
+	switch {
+	case m.isSaving:
+		s.WriteString(m.spinner.View())
+		s.WriteString(" ")
+		s.WriteString(styles.Subtle.Render("Saving..."))
+	case m.errMsg != "":
+		s.WriteString(styles.Error.Render(wordwrap.String(m.errMsg, max(m.width, 10))))
+	}
+	s.WriteString("\n\n")
+	s.WriteString(styles.Subtle.Render("tab/↑↓: next field • ctrl+s: save • esc: cancel"))
+
+	return s.String()
+}
+
+func (m *RuleFormModel) saveCmd(rule azure.RuleInfo) tea.Cmd {
+	client := m.client
+	entityName := m.entityName
+	isCreate := m.rule == nil
+
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), defaultContextTimeout)
+		defer cancel()
+
+		var err error
+		if isCreate {
+			err = client.CreateRule(ctx, entityName, rule)
+		} else {
+			err = client.UpdateRule(ctx, entityName, rule)
+		}
+
+		return RuleSavedMsg{EntityName: entityName, Err: err}
+	}
+}
@@ -0,0 +1,121 @@
+package app
+
+import (
+	"context"
+	"log"
+	"strings"
+
+	"github.com/MonsieurTib/service-bus-tui/internal/azure"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// RulesLoadedMsg carries the rule nodes of the "Rules" node NodeID.
+type RulesLoadedMsg struct {
+	NodeID string
+	Rules  []*TreeNode
+	Err    error
+}
+
+// RuleFocusedMsg shows the filter and action of the selected rule in the detail pane.
+type RuleFocusedMsg struct {
+	EntityName string
+	Rule       azure.RuleInfo
+}
+
+func (n *NamespaceModel) handleRulesLoaded(msg RulesLoadedMsg) tea.Cmd {
+	node := n.findNodeByID(msg.NodeID)
+	if node == nil {
+		return n.restoreTreeState(nil, msg.NodeID)
+	}
+	node.IsLoading = false
+
+	if msg.Err != nil {
+		// Shown under the node, as listing rules needs Manage rights that are often missing.
+		log.Printf("failed to load rules of %s: %v", node.EntityName, msg.Err)
+		node.Children = []*TreeNode{{
+			ID:         node.ID + "-error",
+			Name:       "failed to load rules: " + msg.Err.Error(),
+			Type:       NodeTypeError,
+			EntityName: node.EntityName,
+			Depth:      node.Depth + 1,
+			Children:   []*TreeNode{},
+		}}
+		n.rebuildFlatList()
+		// Settles the load a refresh may be waiting for, with no rules to re-expand.
+		return n.restoreTreeState(nil, msg.NodeID)
+	}
+
+	node.Children = msg.Rules
+	for _, child := range node.Children {
+		child.Depth = node.Depth + 1
+	}
+	n.rebuildFlatList()
+
+	return n.restoreTreeState(msg.Rules, msg.NodeID)
+}
+
+// selectedSubscription returns the subscription of the selected subscription node or of one of its children.
+func (n *NamespaceModel) selectedSubscription() (string, bool) {
+	node := n.selectedNode()
+	if node == nil || node.Type == NodeTypeTopic || node.Type == NodeTypeQueue {
+		return "", false
+	}
+	_, _, isSubscription := strings.Cut(node.EntityName, "/")
+	return node.EntityName, isSubscription
+}
+
+// pickRuleTarget lets the user pick, in the tree, the subscription in which draft is created.
+func (n *NamespaceModel) pickRuleTarget(draft azure.RuleInfo) {
+	n.ruleDraft = &draft
+}
+
+// updateRuleTarget handles keys while a subscription is picked for the draft rule. Navigation keys are not
+// handled, so they move through the tree as usual.
+func (n *NamespaceModel) updateRuleTarget(msg tea.KeyMsg) (tea.Cmd, bool) {
+	switch msg.String() {
+	case "up", "k", "down", "j", "left", "h", "right", "l":
+		return nil, false
+	case "esc":
+		n.ruleDraft = nil
+	case "enter":
+		sub, ok := n.selectedSubscription()
+		if !ok {
+			// Expand topics, so their subscriptions can be reached.
+			return nil, false
+		}
+		req := RuleFormRequestedMsg{EntityName: sub, Draft: n.ruleDraft}
+		n.ruleDraft = nil
+		return func() tea.Msg { return req }, true
+	}
+	return nil, true
+}
+
+func (n *NamespaceModel) loadRulesCmd(node *TreeNode) tea.Cmd {
+	client := n.client
+	nodeID := node.ID
+	entityName := node.EntityName
+
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), defaultContextTimeout)
+		defer cancel()
+
+		rules, err := client.ListRules(ctx, entityName)
+		if err != nil {
+			return RulesLoadedMsg{NodeID: nodeID, Err: err}
+		}
+
+		nodes := make([]*TreeNode, 0, len(rules))
+		for _, rule := range rules {
+			nodes = append(nodes, &TreeNode{
+				ID:         nodeID + "-" + rule.Name,
+				Name:       rule.Name,
+				Type:       NodeTypeRule,
+				EntityName: entityName,
+				Children:   []*TreeNode{},
+				Rule:       &rule,
+			})
+		}
+
+		return RulesLoadedMsg{NodeID: nodeID, Rules: nodes}
+	}
+}
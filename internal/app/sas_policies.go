@@ -0,0 +1,322 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+
+	"github.com/MonsieurTib/service-bus-tui/internal/azure"
+	"github.com/MonsieurTib/service-bus-tui/internal/styles"
+	"github.com/atotto/clipboard"
+	"github.com/charmbracelet/bubbles/spinner"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/muesli/reflow/truncate"
+	"github.com/muesli/reflow/wordwrap"
+	"github.com/muesli/reflow/wrap"
+)
+
+// sasAction is what to do with the keys of the selected policy once they are fetched.
+type sasAction int
+
+const (
+	sasShowKeys sasAction = iota
+	sasCopyConnectionString
+	sasReconnect
+)
+
+// SASPoliciesModel lists the shared access policies of the namespace through ARM, fetches their keys, copies their
+// connection strings and reconnects with them, e.g. to check what a client holding that policy can do.
+type SASPoliciesModel struct {
+	client      *azure.ServiceBusClient
+	namespace   string
+	policies    []azure.SASPolicy
+	keys        map[string]azure.SASKeys // by policy name, once fetched
+	shown       map[string]bool          // policies whose keys are revealed
+	secondary   bool                     // use the secondary key instead of the primary one
+	selectedIdx int
+	loading     bool
+	fetching    bool
+	err         error
+	status      string
+	spinner     spinner.Model
+	width       int
+	height      int
+}
+
+// SASPoliciesRequestedMsg opens the SAS policies of the namespace.
+type SASPoliciesRequestedMsg struct{}
+
+type SASPoliciesClosedMsg struct{}
+
+type sasPoliciesLoadedMsg struct {
+	Policies []azure.SASPolicy
+	Err      error
+}
+
+type sasKeysLoadedMsg struct {
+	Policy string
+	Keys   azure.SASKeys
+	Action sasAction
+	Err    error
+}
+
+// sasStatusMsg reports the outcome of copying a connection string or reconnecting.
+type sasStatusMsg struct {
+	Status string
+	Err    error
+}
+
+func NewSASPoliciesModel(client *azure.ServiceBusClient, namespace string) *SASPoliciesModel {
+	s := spinner.New()
+	s.Spinner = spinner.MiniDot
+
+	return &SASPoliciesModel{
+		client:    client,
+		namespace: namespace,
+		keys:      make(map[string]azure.SASKeys),
+		shown:     make(map[string]bool),
+		loading:   true,
+		spinner:   s,
+	}
+}
+
+func (m *SASPoliciesModel) Init() tea.Cmd {
+	if !m.client.HasResourceManager() {
+		m.loading = false
+		m.err = fmt.Errorf("SAS policies are managed through Azure Resource Manager, which needs an Entra ID sign-in: not available when connected with a connection string or to the emulator")
+		return nil
+	}
+	return tea.Batch(m.spinner.Tick, m.loadPoliciesCmd())
+}
+
+func (m *SASPoliciesModel) Update(msg tea.Msg) (*SASPoliciesModel, tea.Cmd) {
+	var spinnerCmd tea.Cmd
+	m.spinner, spinnerCmd = m.spinner.Update(msg)
+
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "esc":
+			return m, func() tea.Msg { return SASPoliciesClosedMsg{} }
+		case "up", "k":
+			if m.selectedIdx > 0 {
+				m.selectedIdx--
+			}
+		case "down", "j":
+			if m.selectedIdx < len(m.policies)-1 {
+				m.selectedIdx++
+			}
+		case "1":
+			m.secondary = false
+		case "2":
+			m.secondary = true
+		case "v":
+			return m, m.withKeys(sasShowKeys)
+		case "c":
+			return m, m.withKeys(sasCopyConnectionString)
+		case "r":
+			return m, m.withKeys(sasReconnect)
+		}
+		return m, nil
+
+	case sasPoliciesLoadedMsg:
+		m.loading = false
+		m.err = msg.Err
+		m.policies = msg.Policies
+		return m, nil
+
+	case sasKeysLoadedMsg:
+		m.fetching = false
+		if msg.Err != nil {
+			m.status = ""
+			m.err = msg.Err
+			return m, nil
+		}
+		m.err = nil
+		m.keys[msg.Policy] = msg.Keys
+		return m, m.apply(msg.Policy, msg.Action)
+
+	case sasStatusMsg:
+		m.err = msg.Err
+		m.status = msg.Status
+		return m, nil
+	}
+
+	if m.loading || m.fetching {
+		return m, spinnerCmd
+	}
+	return m, nil
+}
+
+// withKeys runs action on the selected policy, fetching its keys first when needed.
+func (m *SASPoliciesModel) withKeys(action sasAction) tea.Cmd {
+	if m.selectedIdx >= len(m.policies) || m.fetching {
+		return nil
+	}
+	name := m.policies[m.selectedIdx].Name
+	if _, ok := m.keys[name]; ok {
+		return m.apply(name, action)
+	}
+
+	m.fetching = true
+	m.status = ""
+	return tea.Batch(m.spinner.Tick, m.loadKeysCmd(name, action))
+}
+
+func (m *SASPoliciesModel) apply(policy string, action sasAction) tea.Cmd {
+	keys := m.keys[policy]
+	which, connStr := "primary", keys.PrimaryConnectionString
+	if m.secondary {
+		which, connStr = "secondary", keys.SecondaryConnectionString
+	}
+
+	switch action {
+	case sasShowKeys:
+		m.shown[policy] = !m.shown[policy]
+		return nil
+
+	case sasCopyConnectionString:
+		return func() tea.Msg {
+			if err := clipboard.WriteAll(connStr); err != nil {
+				return sasStatusMsg{Err: fmt.Errorf("failed to copy to the clipboard: %w", err)}
+			}
+			return sasStatusMsg{Status: fmt.Sprintf("Copied the %s connection string of %s to the clipboard", which, policy)}
+		}
+
+	case sasReconnect:
+		m.status = fmt.Sprintf("Reconnecting with the %s key of %s...", which, policy)
+		namespace := fmt.Sprintf("%s (SAS: %s)", m.namespace, policy)
+		return func() tea.Msg {
+			client, err := azure.NewServiceBusClientFromConnectionString(connStr)
+			if err != nil {
+				return sasStatusMsg{Err: fmt.Errorf("failed to connect with %s: %w", policy, err)}
+			}
+			log.Printf("reconnected to namespace %s with SAS policy %s", client.GetNamespace(), policy)
+			return NamespaceConnectedMsg{Namespace: namespace, Client: client}
+		}
+	}
+	return nil
+}
+
+func (m *SASPoliciesModel) SetSize(width, height int) {
+	m.width = width
+	m.height = height
+}
+
+func (m *SASPoliciesModel) View() string {
+	var s strings.Builder
+
+	s.WriteString(detailHeaderStyle.Render("SAS policies of " + m.namespace))
+	s.WriteString("\n")
+	s.WriteString(detailSeparator)
+	s.WriteString("\n")
+
+	switch {
+	case m.loading:
+		s.WriteString(m.spinner.View())
+		s.WriteString(" ")
+		s.WriteString(styles.Subtle.Render("Loading policies..."))
+		s.WriteString("\n")
+	case len(m.policies) == 0 && m.err == nil:
+		s.WriteString(styles.Subtle.Render("No SAS policies"))
+		s.WriteString("\n")
+	default:
+		m.viewPolicies(&s)
+	}
+
+	if m.selectedIdx < len(m.policies) {
+		m.viewKeys(&s, m.policies[m.selectedIdx])
+	}
+
+	s.WriteString("\n")
+	switch {
+	case m.fetching:
+		s.WriteString(m.spinner.View())
+		s.WriteString(" ")
+		s.WriteString(styles.Subtle.Render("Fetching keys..."))
+		s.WriteString("\n")
+	case m.err != nil:
+		s.WriteString(styles.Error.Render(wordwrap.String(m.err.Error(), max(m.width, 10))))
+		s.WriteString("\n")
+	case m.status != "":
+		s.WriteString(styles.Subtle.Render(m.status))
+		s.WriteString("\n")
+	}
+
+	key := "primary"
+	if m.secondary {
+		key = "secondary"
+	}
+	s.WriteString("\n")
+	s.WriteString(styles.Subtle.Render(wordwrap.String(fmt.Sprintf("key: %s • 1/2: primary/secondary • v: show keys • c: copy connection string • r: reconnect with policy", key), max(m.width, 10))))
+	return s.String()
+}
+
+func (m *SASPoliciesModel) viewPolicies(s *strings.Builder) {
+	nameWidth := 30
+	s.WriteString(detailLabelStyle.Render(fmt.Sprintf("  %-*s  %s", nameWidth, "Name", "Rights")))
+	s.WriteString("\n")
+
+	for i, p := range m.policies {
+		name := truncate.StringWithTail(p.Name, uint(nameWidth), "…")
+		line := fmt.Sprintf("%-*s  %s", nameWidth, name, strings.Join(p.Rights, ", "))
+		if i == m.selectedIdx {
+			s.WriteString(styles.Selected.Render("▶ " + line))
+		} else {
+			s.WriteString("  " + line)
+		}
+		s.WriteString("\n")
+	}
+}
+
+func (m *SASPoliciesModel) viewKeys(s *strings.Builder, p azure.SASPolicy) {
+	if !slices.Contains(p.Rights, "Manage") {
+		s.WriteString("\n")
+		s.WriteString(styles.Subtle.Render("Without Manage rights, the tree can't list entities after reconnecting with this policy"))
+		s.WriteString("\n")
+	}
+
+	keys, ok := m.keys[p.Name]
+	if !ok || !m.shown[p.Name] {
+		return
+	}
+
+	width := max(m.width-17, 20)
+	s.WriteString("\n")
+	for _, field := range []struct{ label, value string }{
+		{"Primary key", keys.PrimaryKey},
+		{"Secondary key", keys.SecondaryKey},
+		{"Primary", keys.PrimaryConnectionString},
+		{"Secondary", keys.SecondaryConnectionString},
+	} {
+		s.WriteString(detailLabelStyle.Render(fmt.Sprintf("%-15s", field.label+":")))
+		s.WriteString(" ")
+		// Keys and connection strings have no spaces to wrap at, so break them at the pane width.
+		s.WriteString(strings.ReplaceAll(wrap.String(field.value, width), "\n", "\n"+strings.Repeat(" ", 16)))
+		s.WriteString("\n")
+	}
+}
+
+func (m *SASPoliciesModel) loadPoliciesCmd() tea.Cmd {
+	client := m.client
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), defaultContextTimeout)
+		defer cancel()
+
+		policies, err := client.ListSASPolicies(ctx)
+		return sasPoliciesLoadedMsg{Policies: policies, Err: err}
+	}
+}
+
+func (m *SASPoliciesModel) loadKeysCmd(policy string, action sasAction) tea.Cmd {
+	client := m.client
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), defaultContextTimeout)
+		defer cancel()
+
+		keys, err := client.GetSASKeys(ctx, policy)
+		return sasKeysLoadedMsg{Policy: policy, Keys: keys, Action: action, Err: err}
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"os/exec"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
@@ -23,20 +24,29 @@ const (
 	interactiveBrowserRedirectURL = "http://localhost:8080"
 	defaultContextTimeout         = 30 * time.Second
 	azureAPIVersion               = "2020-01-01"
-	azureManagementURL            = "https://management.azure.com"
-	azureManagementScope          = "https://management.azure.com/.default"
 )
 
 type ServiceBusClient struct {
 	client      *azservicebus.Client
 	adminClient *admin.Client
 	namespace   string
+	cred        azcore.TokenCredential // nil when connected with a connection string
+	emulator    bool                   // connected to the local Service Bus emulator
+
+	armMu          sync.Mutex
+	subscriptionID string // subscription and resource group of the namespace, for ARM calls
+	resourceGroup  string
 }
 
 func (sbc *ServiceBusClient) GetNamespace() string {
 	return sbc.namespace
 }
 
+// Close closes the AMQP connection of the client.
+func (sbc *ServiceBusClient) Close(ctx context.Context) error {
+	return sbc.client.Close(ctx)
+}
+
 type NamespaceInfo struct {
 	Name           string
 	FullyQualified string
@@ -47,13 +57,45 @@ type NamespaceInfo struct {
 
 // MessageInfo I choose "MessageInfo" instead of "Message" to avoid confusion/conflict with azservicebus package
 type MessageInfo struct {
-	MessageID      string
-	SequenceNumber int64
-	Subject        string
-	Body           string
-	EnqueuedTime   time.Time
-	ContentType    string
-	Properties     map[string]any
+	MessageID            string
+	SequenceNumber       int64
+	Subject              string
+	Body                 string
+	EnqueuedTime         time.Time
+	ContentType          string
+	CorrelationID        string
+	SessionID            string
+	ReplyTo              string
+	ReplyToSessionID     string
+	To                   string
+	PartitionKey         string
+	TimeToLive           time.Duration
+	ScheduledEnqueueTime time.Time
+	ExpiresAt            time.Time
+	LockedUntil          time.Time
+	DeliveryCount        uint32
+	State                string // MessageStateActive, MessageStateDeferred or MessageStateScheduled
+	Properties           map[string]any
+
+	// Set on dead-lettered messages.
+	DeadLetterReason           string
+	DeadLetterErrorDescription string
+	DeadLetterSource           string
+}
+
+const (
+	MessageStateActive    = "Active"
+	MessageStateDeferred  = "Deferred"
+	MessageStateScheduled = "Scheduled"
+)
+
+// MessageCounts holds the runtime message counts of a queue or subscription.
+type MessageCounts struct {
+	Active             int32
+	DeadLetter         int32
+	Scheduled          int32
+	Transfer           int32
+	TransferDeadLetter int32
 }
 
 func GetAzureCliAuthenticatedUser() (string, bool) {
@@ -93,45 +135,42 @@ func NewServiceBusClientFromInteractiveBrowser(namespace string) (*ServiceBusCli
 	return newServiceBusClientWithCredential(cred, namespace)
 }
 
+// NewServiceBusClientFromConnectionString connects with a SAS connection string. Besides namespaces in Azure, it
+// accepts custom endpoints such as "sb://localhost", and the local emulator with UseDevelopmentEmulator=true.
 func NewServiceBusClientFromConnectionString(connectionString string) (*ServiceBusClient, error) {
+	props, err := parseConnectionString(connectionString)
+	if err != nil {
+		return nil, err
+	}
+
 	client, err := azservicebus.NewClientFromConnectionString(connectionString, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create service bus client from connection string: %w", err)
 	}
 
-	adminClient, err := admin.NewClientFromConnectionString(connectionString, nil)
+	adminClient, err := admin.NewClientFromConnectionString(props.adminConnectionString(connectionString), nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create admin client from connection string: %w", err)
 	}
 
-	namespace := parseNamespaceFromConnectionString(connectionString)
-
 	return &ServiceBusClient{
 		client:      client,
 		adminClient: adminClient,
-		namespace:   namespace,
+		namespace:   props.namespaceName(),
+		emulator:    props.emulator,
 	}, nil
 }
 
-func parseNamespaceFromConnectionString(connectionString string) string {
-	for part := range strings.SplitSeq(connectionString, ";") {
-		part = strings.TrimSpace(part)
-		if strings.HasPrefix(strings.ToLower(part), "endpoint=") {
-			endpoint := part[len("endpoint="):]
-			endpoint = strings.TrimPrefix(endpoint, "sb://")
-			endpoint = strings.TrimSuffix(endpoint, "/")
-			if idx := strings.Index(endpoint, "."); idx > 0 {
-				return endpoint[:idx]
-			}
-			return endpoint
-		}
-	}
-	return ""
+// IsEmulator reports whether the client is connected to the local Service Bus emulator, which has no Azure
+// Resource Manager or Entra ID behind it.
+func (sbc *ServiceBusClient) IsEmulator() bool {
+	return sbc.emulator
 }
 
 func newInteractiveBrowserCredential() (azcore.TokenCredential, error) {
 	opts := &azidentity.InteractiveBrowserCredentialOptions{
-		RedirectURL: interactiveBrowserRedirectURL,
+		ClientOptions: clientOptions(),
+		RedirectURL:   interactiveBrowserRedirectURL,
 	}
 	cred, err := azidentity.NewInteractiveBrowserCredential(opts)
 	if err != nil {
@@ -141,7 +180,7 @@ func newInteractiveBrowserCredential() (azcore.TokenCredential, error) {
 }
 
 func newServiceBusClientWithCredential(cred azcore.TokenCredential, namespace string) (*ServiceBusClient, error) {
-	fqdn := fmt.Sprintf("%s.servicebus.windows.net", namespace)
+	fqdn := namespaceHost(namespace)
 
 	client, err := azservicebus.NewClient(fqdn, cred, nil)
 	if err != nil {
@@ -157,9 +196,21 @@ func newServiceBusClientWithCredential(cred azcore.TokenCredential, namespace st
 		client:      client,
 		adminClient: adminClient,
 		namespace:   namespace,
+		cred:        cred,
 	}, nil
 }
 
+// WithNamespace returns a client for another namespace that reuses the credential of sbc.
+func (sbc *ServiceBusClient) WithNamespace(namespace string) (*ServiceBusClient, error) {
+	if sbc.emulator {
+		return nil, fmt.Errorf("the emulator has no other namespaces, use a connection string for %s", namespace)
+	}
+	if sbc.cred == nil {
+		return nil, fmt.Errorf("connected with a connection string, use a connection string for %s too", namespace)
+	}
+	return newServiceBusClientWithCredential(sbc.cred, namespace)
+}
+
 func GetNamespacesForAzureCLI(ctx context.Context) ([]NamespaceInfo, error) {
 	cred, err := azidentity.NewAzureCLICredential(nil)
 	if err != nil {
@@ -188,7 +239,7 @@ func getNamespaces(ctx context.Context, cred azcore.TokenCredential) ([]Namespac
 	var namespaces []NamespaceInfo
 
 	for _, subID := range subscriptions {
-		nsClient, err := armservicebus.NewNamespacesClient(subID, cred, nil)
+		nsClient, err := armservicebus.NewNamespacesClient(subID, cred, armClientOptions())
 		if err != nil {
 			continue
 		}
@@ -215,7 +266,7 @@ func getNamespaces(ctx context.Context, cred azcore.TokenCredential) ([]Namespac
 					resourceGroup = extractResourceGroup(*ns.ID)
 				}
 
-				fqdn := fmt.Sprintf("%s.servicebus.windows.net", *ns.Name)
+				fqdn := namespaceHost(*ns.Name)
 
 				namespaces = append(namespaces, NamespaceInfo{
 					Name:           *ns.Name,
@@ -239,9 +290,10 @@ func listSubscriptions(ctx context.Context, cred azcore.TokenCredential) ([]stri
 	ctx, cancel := context.WithTimeout(ctx, defaultContextTimeout)
 	defer cancel()
 
+	azureCloud := CurrentCloud()
 	bearerPolicy := runtime.NewBearerTokenPolicy(
 		cred,
-		[]string{azureManagementScope},
+		[]string{azureCloud.managementScope()},
 		nil,
 	)
 
@@ -257,7 +309,7 @@ func listSubscriptions(ctx context.Context, cred azcore.TokenCredential) ([]stri
 	req, err := runtime.NewRequest(
 		ctx,
 		http.MethodGet,
-		azureManagementURL+"/subscriptions?api-version="+azureAPIVersion,
+		azureCloud.ResourceManagerURL+"/subscriptions?api-version="+azureAPIVersion,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
@@ -364,70 +416,166 @@ func (sbc *ServiceBusClient) ListSubscriptions(ctx context.Context, topicName st
 	return subscriptions, nil
 }
 
-func (sbc *ServiceBusClient) PeekMessages(ctx context.Context, entityName string, isDeadLetter bool, maxMessages int) ([]MessageInfo, error) {
-	// entityName format: "topic/subscription" or "queue"
-	parts := strings.SplitN(entityName, "/", 2)
-	if len(parts) != 2 {
-		return nil, fmt.Errorf("invalid entity name format: %s (expected 'topic/subscription')", entityName)
+// ListQueueMessageCounts returns the message counts of every queue, keyed by queue name.
+func (sbc *ServiceBusClient) ListQueueMessageCounts(ctx context.Context) (map[string]MessageCounts, error) {
+	pager := sbc.adminClient.NewListQueuesRuntimePropertiesPager(nil)
+	counts := make(map[string]MessageCounts)
+
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list queue runtime properties: %w", err)
+		}
+
+		for _, queue := range page.QueueRuntimeProperties {
+			counts[queue.QueueName] = MessageCounts{
+				Active:             queue.ActiveMessageCount,
+				DeadLetter:         queue.DeadLetterMessageCount,
+				Scheduled:          queue.ScheduledMessageCount,
+				Transfer:           queue.TransferMessageCount,
+				TransferDeadLetter: queue.TransferDeadLetterMessageCount,
+			}
+		}
 	}
-	topicName := parts[0]
-	subscriptionName := parts[1]
 
-	var receiver *azservicebus.Receiver
-	var err error
+	return counts, nil
+}
 
-	if isDeadLetter {
-		receiver, err = sbc.client.NewReceiverForSubscription(
-			topicName,
-			subscriptionName,
-			&azservicebus.ReceiverOptions{
-				SubQueue: azservicebus.SubQueueDeadLetter,
-			},
-		)
-	} else {
-		receiver, err = sbc.client.NewReceiverForSubscription(
-			topicName,
-			subscriptionName,
-			nil,
-		)
+// ListSubscriptionMessageCounts returns the message counts of every subscription of topicName, keyed by
+// "topic/subscription".
+func (sbc *ServiceBusClient) ListSubscriptionMessageCounts(ctx context.Context, topicName string) (map[string]MessageCounts, error) {
+	pager := sbc.adminClient.NewListSubscriptionsRuntimePropertiesPager(topicName, nil)
+	counts := make(map[string]MessageCounts)
+
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list subscription runtime properties for topic %s: %w", topicName, err)
+		}
+
+		for _, sub := range page.SubscriptionRuntimeProperties {
+			counts[topicName+"/"+sub.SubscriptionName] = MessageCounts{
+				Active:             sub.ActiveMessageCount,
+				DeadLetter:         sub.DeadLetterMessageCount,
+				Transfer:           sub.TransferMessageCount,
+				TransferDeadLetter: sub.TransferDeadLetterMessageCount,
+			}
+		}
 	}
 
+	return counts, nil
+}
+
+// PeekMessages peeks up to maxMessages starting at fromSequenceNumber. A fromSequenceNumber of 0
+// starts at the oldest available message.
+func (sbc *ServiceBusClient) PeekMessages(ctx context.Context, entityName string, isDeadLetter bool, fromSequenceNumber int64, maxMessages int) ([]MessageInfo, error) {
+	receiver, err := sbc.newReceiver(entityName, isDeadLetter, nil)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create receiver: %w", err)
+		return nil, err
 	}
 	defer receiver.Close(ctx)
 
-	peekedMessages, err := receiver.PeekMessages(ctx, maxMessages, nil)
+	var peekOpts *azservicebus.PeekMessagesOptions
+	if fromSequenceNumber > 0 {
+		peekOpts = &azservicebus.PeekMessagesOptions{
+			FromSequenceNumber: &fromSequenceNumber,
+		}
+	}
+
+	peekedMessages, err := receiver.PeekMessages(ctx, maxMessages, peekOpts)
 	if err != nil {
 		return nil, fmt.Errorf("failed to peek messages: %w", err)
 	}
 
 	var result []MessageInfo
 	for _, msg := range peekedMessages {
-		pm := MessageInfo{
-			SequenceNumber: *msg.SequenceNumber,
-			MessageID:      msg.MessageID,
-			Properties:     msg.ApplicationProperties,
-		}
+		result = append(result, newMessageInfo(msg))
+	}
 
-		if msg.Subject != nil {
-			pm.Subject = *msg.Subject
-		}
+	return result, nil
+}
 
-		if msg.ContentType != nil {
-			pm.ContentType = *msg.ContentType
-		}
+// newMessageInfo copies the body, application properties and broker metadata of a received message.
+func newMessageInfo(msg *azservicebus.ReceivedMessage) MessageInfo {
+	pm := MessageInfo{
+		SequenceNumber:             *msg.SequenceNumber,
+		MessageID:                  msg.MessageID,
+		Properties:                 msg.ApplicationProperties,
+		Subject:                    derefString(msg.Subject),
+		ContentType:                derefString(msg.ContentType),
+		CorrelationID:              derefString(msg.CorrelationID),
+		SessionID:                  derefString(msg.SessionID),
+		ReplyTo:                    derefString(msg.ReplyTo),
+		ReplyToSessionID:           derefString(msg.ReplyToSessionID),
+		To:                         derefString(msg.To),
+		PartitionKey:               derefString(msg.PartitionKey),
+		DeliveryCount:              msg.DeliveryCount,
+		DeadLetterReason:           derefString(msg.DeadLetterReason),
+		DeadLetterErrorDescription: derefString(msg.DeadLetterErrorDescription),
+		DeadLetterSource:           derefString(msg.DeadLetterSource),
+	}
 
-		if msg.EnqueuedTime != nil {
-			pm.EnqueuedTime = *msg.EnqueuedTime
-		}
+	switch msg.State {
+	case azservicebus.MessageStateDeferred:
+		pm.State = MessageStateDeferred
+	case azservicebus.MessageStateScheduled:
+		pm.State = MessageStateScheduled
+	default:
+		pm.State = MessageStateActive
+	}
 
-		if msg.Body != nil {
-			pm.Body = string(msg.Body)
-		}
+	if msg.TimeToLive != nil {
+		pm.TimeToLive = *msg.TimeToLive
+	}
 
-		result = append(result, pm)
+	if msg.ScheduledEnqueueTime != nil {
+		pm.ScheduledEnqueueTime = *msg.ScheduledEnqueueTime
 	}
 
-	return result, nil
+	if msg.EnqueuedTime != nil {
+		pm.EnqueuedTime = *msg.EnqueuedTime
+	}
+
+	if msg.ExpiresAt != nil {
+		pm.ExpiresAt = *msg.ExpiresAt
+	}
+
+	if msg.LockedUntil != nil {
+		pm.LockedUntil = *msg.LockedUntil
+	}
+
+	if msg.Body != nil {
+		pm.Body = string(msg.Body)
+	}
+
+	return pm
+}
+
+// newReceiver creates a receiver for entityName, which is either "topic/subscription" or "queue".
+// When isDeadLetter is set the receiver targets the dead-letter subqueue.
+func (sbc *ServiceBusClient) newReceiver(entityName string, isDeadLetter bool, opts *azservicebus.ReceiverOptions) (*azservicebus.Receiver, error) {
+	if entityName == "" {
+		return nil, fmt.Errorf("entity name cannot be empty")
+	}
+
+	if opts == nil {
+		opts = &azservicebus.ReceiverOptions{}
+	}
+	if isDeadLetter {
+		opts.SubQueue = azservicebus.SubQueueDeadLetter
+	}
+
+	var receiver *azservicebus.Receiver
+	var err error
+
+	if topicName, subscriptionName, ok := strings.Cut(entityName, "/"); ok {
+		receiver, err = sbc.client.NewReceiverForSubscription(topicName, subscriptionName, opts)
+	} else {
+		receiver, err = sbc.client.NewReceiverForQueue(entityName, opts)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to create receiver: %w", err)
+	}
+	return receiver, nil
 }
@@ -0,0 +1,126 @@
+package azure
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/cloud"
+)
+
+const (
+	CloudPublic       = "public"
+	CloudUSGovernment = "usgov"
+	CloudChina        = "china"
+	// CloudCustom reads its endpoints from AZURE_AUTHORITY_HOST, AZURE_RESOURCE_MANAGER_URL and
+	// AZURE_SERVICEBUS_DNS_SUFFIX, e.g. for Azure Stack Hub.
+	CloudCustom = "custom"
+)
+
+// CloudNames lists the clouds accepted by CloudByName.
+var CloudNames = []string{CloudPublic, CloudUSGovernment, CloudChina, CloudCustom}
+
+// Cloud holds the endpoints that differ between the public Azure cloud and the sovereign clouds.
+type Cloud struct {
+	Name               string
+	AuthorityHost      string // Entra ID, e.g. "https://login.microsoftonline.com/"
+	ResourceManagerURL string // e.g. "https://management.azure.com"
+	ServiceBusSuffix   string // DNS suffix of namespaces, e.g. ".servicebus.windows.net"
+}
+
+var (
+	publicCloud = Cloud{
+		Name:               CloudPublic,
+		AuthorityHost:      "https://login.microsoftonline.com/",
+		ResourceManagerURL: "https://management.azure.com",
+		ServiceBusSuffix:   ".servicebus.windows.net",
+	}
+	usGovernmentCloud = Cloud{
+		Name:               CloudUSGovernment,
+		AuthorityHost:      "https://login.microsoftonline.us/",
+		ResourceManagerURL: "https://management.usgovcloudapi.net",
+		ServiceBusSuffix:   ".servicebus.usgovcloudapi.net",
+	}
+	chinaCloud = Cloud{
+		Name:               CloudChina,
+		AuthorityHost:      "https://login.chinacloudapi.cn/",
+		ResourceManagerURL: "https://management.chinacloudapi.cn",
+		ServiceBusSuffix:   ".servicebus.chinacloudapi.cn",
+	}
+)
+
+// CloudByName returns the cloud named name, one of CloudNames. An empty name selects the public cloud.
+func CloudByName(name string) (Cloud, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "", CloudPublic, "azurecloud":
+		return publicCloud, nil
+	case CloudUSGovernment, "azureusgovernment":
+		return usGovernmentCloud, nil
+	case CloudChina, "azurechinacloud":
+		return chinaCloud, nil
+	case CloudCustom:
+		return customCloudFromEnv()
+	}
+	return Cloud{}, fmt.Errorf("unknown cloud %q, expected one of %s", name, strings.Join(CloudNames, ", "))
+}
+
+func customCloudFromEnv() (Cloud, error) {
+	c := Cloud{
+		Name:               CloudCustom,
+		AuthorityHost:      os.Getenv("AZURE_AUTHORITY_HOST"),
+		ResourceManagerURL: strings.TrimSuffix(os.Getenv("AZURE_RESOURCE_MANAGER_URL"), "/"),
+		ServiceBusSuffix:   os.Getenv("AZURE_SERVICEBUS_DNS_SUFFIX"),
+	}
+	if c.AuthorityHost == "" || c.ResourceManagerURL == "" || c.ServiceBusSuffix == "" {
+		return Cloud{}, fmt.Errorf("custom cloud requires AZURE_AUTHORITY_HOST, AZURE_RESOURCE_MANAGER_URL and AZURE_SERVICEBUS_DNS_SUFFIX")
+	}
+	if !strings.HasPrefix(c.ServiceBusSuffix, ".") {
+		c.ServiceBusSuffix = "." + c.ServiceBusSuffix
+	}
+	return c, nil
+}
+
+var (
+	cloudMu      sync.RWMutex
+	currentCloud = publicCloud
+)
+
+// SetCloud selects the cloud used by credentials, namespace discovery and clients created afterwards.
+func SetCloud(c Cloud) {
+	cloudMu.Lock()
+	defer cloudMu.Unlock()
+	currentCloud = c
+}
+
+// CurrentCloud returns the cloud selected with SetCloud, the public cloud by default.
+func CurrentCloud() Cloud {
+	cloudMu.RLock()
+	defer cloudMu.RUnlock()
+	return currentCloud
+}
+
+// managementScope is the token scope of Azure Resource Manager in c.
+func (c Cloud) managementScope() string {
+	return c.ResourceManagerURL + "/.default"
+}
+
+func (c Cloud) configuration() cloud.Configuration {
+	return cloud.Configuration{
+		ActiveDirectoryAuthorityHost: c.AuthorityHost,
+		Services: map[cloud.ServiceName]cloud.ServiceConfiguration{
+			cloud.ResourceManager: {Audience: c.ResourceManagerURL, Endpoint: c.ResourceManagerURL},
+		},
+	}
+}
+
+// clientOptions returns the options that point credentials and ARM clients at the current cloud.
+func clientOptions() azcore.ClientOptions {
+	return azcore.ClientOptions{Cloud: CurrentCloud().configuration()}
+}
+
+func armClientOptions() *arm.ClientOptions {
+	return &arm.ClientOptions{ClientOptions: clientOptions()}
+}
@@ -0,0 +1,244 @@
+package azure
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+)
+
+// ServicePrincipal holds the settings of an app registration, authenticated with either a client secret or a
+// certificate.
+type ServicePrincipal struct {
+	TenantID            string
+	ClientID            string
+	ClientSecret        string
+	CertificatePath     string // PEM or PKCS#12 file holding the certificate and its private key
+	CertificatePassword string
+}
+
+// ServicePrincipalFromEnv reads the service principal from the environment variables also used by the Azure SDKs
+// and CLI: AZURE_TENANT_ID, AZURE_CLIENT_ID, AZURE_CLIENT_SECRET, AZURE_CLIENT_CERTIFICATE_PATH and
+// AZURE_CLIENT_CERTIFICATE_PASSWORD.
+func ServicePrincipalFromEnv() ServicePrincipal {
+	return ServicePrincipal{
+		TenantID:            os.Getenv("AZURE_TENANT_ID"),
+		ClientID:            os.Getenv("AZURE_CLIENT_ID"),
+		ClientSecret:        os.Getenv("AZURE_CLIENT_SECRET"),
+		CertificatePath:     os.Getenv("AZURE_CLIENT_CERTIFICATE_PATH"),
+		CertificatePassword: os.Getenv("AZURE_CLIENT_CERTIFICATE_PASSWORD"),
+	}
+}
+
+// newServicePrincipalCredential authenticates with the certificate of sp when it has a certificate path, and with
+// its client secret otherwise.
+func newServicePrincipalCredential(sp ServicePrincipal) (azcore.TokenCredential, error) {
+	if sp.TenantID == "" || sp.ClientID == "" {
+		return nil, fmt.Errorf("tenant ID and client ID are required")
+	}
+
+	if sp.CertificatePath == "" {
+		if sp.ClientSecret == "" {
+			return nil, fmt.Errorf("client secret or certificate path is required")
+		}
+		cred, err := azidentity.NewClientSecretCredential(sp.TenantID, sp.ClientID, sp.ClientSecret,
+			&azidentity.ClientSecretCredentialOptions{ClientOptions: clientOptions()})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create client secret credential: %w", err)
+		}
+		return cred, nil
+	}
+
+	data, err := os.ReadFile(sp.CertificatePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read certificate: %w", err)
+	}
+
+	var password []byte
+	if sp.CertificatePassword != "" {
+		password = []byte(sp.CertificatePassword)
+	}
+	certs, key, err := azidentity.ParseCertificates(data, password)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse certificate %s: %w", sp.CertificatePath, err)
+	}
+
+	cred, err := azidentity.NewClientCertificateCredential(sp.TenantID, sp.ClientID, certs, key,
+		&azidentity.ClientCertificateCredentialOptions{ClientOptions: clientOptions()})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create client certificate credential: %w", err)
+	}
+	return cred, nil
+}
+
+func NewServiceBusClientFromServicePrincipal(sp ServicePrincipal, namespace string) (*ServiceBusClient, error) {
+	cred, err := newServicePrincipalCredential(sp)
+	if err != nil {
+		return nil, err
+	}
+	return newServiceBusClientWithCredential(cred, namespace)
+}
+
+func GetNamespacesForServicePrincipal(ctx context.Context, sp ServicePrincipal) ([]NamespaceInfo, error) {
+	cred, err := newServicePrincipalCredential(sp)
+	if err != nil {
+		return nil, err
+	}
+	return getNamespaces(ctx, cred)
+}
+
+// DeviceCodePrompt tells the user where to enter the code that completes a device code login.
+type DeviceCodePrompt struct {
+	UserCode        string
+	VerificationURL string
+	Message         string
+}
+
+// DeviceCodeCredential signs in with the device code flow, which works without a local browser, e.g. over SSH.
+// The same credential must be used to list namespaces and to connect, so the user signs in only once.
+type DeviceCodeCredential struct {
+	cred azcore.TokenCredential
+}
+
+// NewDeviceCodeCredential creates a device code credential for tenantID, or for the "organizations" tenant when it
+// is empty. prompt is called, from the goroutine requesting a token, when the user has to enter a code.
+func NewDeviceCodeCredential(tenantID string, prompt func(DeviceCodePrompt)) (*DeviceCodeCredential, error) {
+	cred, err := azidentity.NewDeviceCodeCredential(&azidentity.DeviceCodeCredentialOptions{
+		ClientOptions: clientOptions(),
+		TenantID:      tenantID,
+		UserPrompt: func(_ context.Context, msg azidentity.DeviceCodeMessage) error {
+			prompt(DeviceCodePrompt{UserCode: msg.UserCode, VerificationURL: msg.VerificationURL, Message: msg.Message})
+			return nil
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create device code credential: %w", err)
+	}
+	return &DeviceCodeCredential{cred: cred}, nil
+}
+
+func NewServiceBusClientFromDeviceCode(dc *DeviceCodeCredential, namespace string) (*ServiceBusClient, error) {
+	return newServiceBusClientWithCredential(dc.cred, namespace)
+}
+
+// GetNamespacesForDeviceCode signs in before listing namespaces. ctx must leave the user enough time to enter the
+// code, which takes longer than listing namespaces.
+func GetNamespacesForDeviceCode(ctx context.Context, dc *DeviceCodeCredential) ([]NamespaceInfo, error) {
+	if _, err := dc.cred.GetToken(ctx, policy.TokenRequestOptions{Scopes: []string{CurrentCloud().managementScope()}}); err != nil {
+		return nil, fmt.Errorf("device code login failed: %w", err)
+	}
+	return getNamespaces(ctx, dc.cred)
+}
+
+const (
+	ManagedIdentitySystemAssigned = "system-assigned"
+	ManagedIdentityUserAssigned   = "user-assigned"
+	ManagedIdentityWorkload       = "workload"
+	// ManagedIdentityAuto tries, in order, a service principal from the environment, workload identity and the
+	// managed identity of the host, like DefaultAzureCredential without the developer tools.
+	ManagedIdentityAuto = "auto"
+)
+
+// managedIdentityProbeTimeout bounds the token request that detects a managed identity endpoint, which only
+// answers on Azure hosts.
+const managedIdentityProbeTimeout = 3 * time.Second
+
+// ManagedIdentity selects the identity of the Azure host the tool runs on, such as a VM or an AKS pod.
+type ManagedIdentity struct {
+	Kind     string // one of the ManagedIdentity* constants
+	ClientID string // client ID of a user-assigned identity
+}
+
+func newManagedIdentityCredential(mi ManagedIdentity) (azcore.TokenCredential, error) {
+	switch mi.Kind {
+	case ManagedIdentitySystemAssigned:
+		return azidentity.NewManagedIdentityCredential(nil)
+
+	case ManagedIdentityUserAssigned:
+		if mi.ClientID == "" {
+			return nil, fmt.Errorf("client ID of the user-assigned identity is required")
+		}
+		return azidentity.NewManagedIdentityCredential(&azidentity.ManagedIdentityCredentialOptions{
+			ID: azidentity.ClientID(mi.ClientID),
+		})
+
+	case ManagedIdentityWorkload:
+		cred, err := azidentity.NewWorkloadIdentityCredential(&azidentity.WorkloadIdentityCredentialOptions{ClientOptions: clientOptions()})
+		if err != nil {
+			return nil, fmt.Errorf("workload identity is not configured (AZURE_CLIENT_ID, AZURE_TENANT_ID and AZURE_FEDERATED_TOKEN_FILE): %w", err)
+		}
+		return cred, nil
+
+	case ManagedIdentityAuto:
+		// Credentials that can't be configured from the environment are left out of the chain.
+		var sources []azcore.TokenCredential
+		if cred, err := azidentity.NewEnvironmentCredential(&azidentity.EnvironmentCredentialOptions{ClientOptions: clientOptions()}); err == nil {
+			sources = append(sources, cred)
+		}
+		if cred, err := azidentity.NewWorkloadIdentityCredential(&azidentity.WorkloadIdentityCredentialOptions{ClientOptions: clientOptions()}); err == nil {
+			sources = append(sources, cred)
+		}
+		if cred, err := azidentity.NewManagedIdentityCredential(hostManagedIdentityOptions()); err == nil {
+			sources = append(sources, cred)
+		}
+		return azidentity.NewChainedTokenCredential(sources, nil)
+	}
+
+	return nil, fmt.Errorf("unknown managed identity kind %q", mi.Kind)
+}
+
+// hostManagedIdentityOptions selects the user-assigned identity whose client ID is in AZURE_CLIENT_ID, as
+// DefaultAzureCredential does, so hosts with only a user-assigned identity work. Without it, the system-assigned
+// identity is used.
+func hostManagedIdentityOptions() *azidentity.ManagedIdentityCredentialOptions {
+	if clientID := os.Getenv("AZURE_CLIENT_ID"); clientID != "" {
+		return &azidentity.ManagedIdentityCredentialOptions{ID: azidentity.ClientID(clientID)}
+	}
+	return nil
+}
+
+// DetectManagedIdentity reports whether the environment or the host provides an identity, and describes it. Only
+// the managed identity of the host requires a request, which is bounded by a short timeout.
+func DetectManagedIdentity(ctx context.Context) (string, bool) {
+	sp := ServicePrincipalFromEnv()
+	if sp.TenantID != "" && sp.ClientID != "" && (sp.ClientSecret != "" || sp.CertificatePath != "") {
+		return "service principal from environment", true
+	}
+	if os.Getenv("AZURE_FEDERATED_TOKEN_FILE") != "" && sp.ClientID != "" {
+		return "workload identity", true
+	}
+	if os.Getenv("IDENTITY_ENDPOINT") != "" || os.Getenv("MSI_ENDPOINT") != "" {
+		return "managed identity", true
+	}
+
+	cred, err := azidentity.NewManagedIdentityCredential(hostManagedIdentityOptions())
+	if err != nil {
+		return "", false
+	}
+	ctx, cancel := context.WithTimeout(ctx, managedIdentityProbeTimeout)
+	defer cancel()
+	if _, err := cred.GetToken(ctx, policy.TokenRequestOptions{Scopes: []string{CurrentCloud().managementScope()}}); err != nil {
+		return "", false
+	}
+	return "managed identity", true
+}
+
+func NewServiceBusClientFromManagedIdentity(mi ManagedIdentity, namespace string) (*ServiceBusClient, error) {
+	cred, err := newManagedIdentityCredential(mi)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s identity credential: %w", mi.Kind, err)
+	}
+	return newServiceBusClientWithCredential(cred, namespace)
+}
+
+func GetNamespacesForManagedIdentity(ctx context.Context, mi ManagedIdentity) ([]NamespaceInfo, error) {
+	cred, err := newManagedIdentityCredential(mi)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s identity credential: %w", mi.Kind, err)
+	}
+	return getNamespaces(ctx, cred)
+}
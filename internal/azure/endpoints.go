@@ -0,0 +1,89 @@
+package azure
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// emulatorAdminPort is where the Service Bus emulator serves management operations; messaging uses AMQP on the
+// host and port of the connection string. With UseDevelopmentEmulator=true, azservicebus switches both clients to
+// plain AMQP and HTTP, but still takes the port from the connection string.
+const emulatorAdminPort = "5300"
+
+// connectionStringProperties holds the parts of a connection string the client needs besides the credentials.
+type connectionStringProperties struct {
+	host     string // host of the endpoint, e.g. "myns.servicebus.windows.net" or "localhost"
+	port     string // port of the endpoint, "" when it is not set
+	emulator bool   // UseDevelopmentEmulator=true
+}
+
+func parseConnectionString(connectionString string) (connectionStringProperties, error) {
+	var props connectionStringProperties
+
+	for part := range strings.SplitSeq(connectionString, ";") {
+		key, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			continue
+		}
+		switch strings.ToLower(strings.TrimSpace(key)) {
+		case "endpoint":
+			endpoint := strings.TrimSpace(value)
+			if i := strings.Index(endpoint, "://"); i >= 0 {
+				endpoint = endpoint[i+len("://"):]
+			}
+			endpoint = strings.TrimSuffix(endpoint, "/")
+			props.host, props.port = endpoint, ""
+			if host, port, err := net.SplitHostPort(endpoint); err == nil {
+				props.host, props.port = host, port
+			}
+		case "usedevelopmentemulator":
+			props.emulator = strings.EqualFold(strings.TrimSpace(value), "true")
+		}
+	}
+
+	if props.host == "" {
+		return props, fmt.Errorf("connection string has no Endpoint")
+	}
+	return props, nil
+}
+
+// namespaceName returns the name shown for the namespace: the first label of a Service Bus host name in any of the
+// clouds, or the whole endpoint for custom endpoints such as the emulator's "localhost".
+func (p connectionStringProperties) namespaceName() string {
+	for _, c := range []Cloud{CurrentCloud(), publicCloud, usGovernmentCloud, chinaCloud} {
+		if name, ok := strings.CutSuffix(strings.ToLower(p.host), c.ServiceBusSuffix); ok && !strings.Contains(name, ".") {
+			return name
+		}
+	}
+	if p.port != "" {
+		return net.JoinHostPort(p.host, p.port)
+	}
+	return p.host
+}
+
+// adminConnectionString returns the connection string of the management endpoint. The emulator serves it on its
+// own port; namespaces in Azure serve both on the same host.
+func (p connectionStringProperties) adminConnectionString(connectionString string) string {
+	if !p.emulator {
+		return connectionString
+	}
+
+	parts := strings.Split(connectionString, ";")
+	for i, part := range parts {
+		key, _, _ := strings.Cut(strings.TrimSpace(part), "=")
+		if strings.EqualFold(strings.TrimSpace(key), "endpoint") {
+			parts[i] = "Endpoint=sb://" + net.JoinHostPort(p.host, emulatorAdminPort)
+		}
+	}
+	return strings.Join(parts, ";")
+}
+
+// namespaceHost returns the host name of namespace. A bare name such as "myns" gets the Service Bus DNS suffix of
+// the current cloud, while a host name, with or without a port, is used as is.
+func namespaceHost(namespace string) string {
+	if strings.ContainsAny(namespace, ".:") || namespace == "localhost" {
+		return namespace
+	}
+	return namespace + CurrentCloud().ServiceBusSuffix
+}
@@ -0,0 +1,130 @@
+package azure
+
+import "testing"
+
+func TestParseConnectionString(t *testing.T) {
+	tests := []struct {
+		connectionString string
+		want             connectionStringProperties
+		wantErr          bool
+	}{
+		{
+			connectionString: "Endpoint=sb://myns.servicebus.windows.net/;SharedAccessKeyName=RootManageSharedAccessKey;SharedAccessKey=abc=",
+			want:             connectionStringProperties{host: "myns.servicebus.windows.net"},
+		},
+		{
+			connectionString: "Endpoint=sb://localhost;SharedAccessKeyName=RootManageSharedAccessKey;SharedAccessKey=SAS_KEY_VALUE;UseDevelopmentEmulator=true;",
+			want:             connectionStringProperties{host: "localhost", emulator: true},
+		},
+		{
+			connectionString: "endpoint=sb://localhost:5672/; usedevelopmentemulator = TRUE",
+			want:             connectionStringProperties{host: "localhost", port: "5672", emulator: true},
+		},
+		{
+			connectionString: "Endpoint=sb://[::1]:5672;UseDevelopmentEmulator=false",
+			want:             connectionStringProperties{host: "::1", port: "5672"},
+		},
+		{
+			connectionString: "Endpoint=myns.servicebus.usgovcloudapi.net",
+			want:             connectionStringProperties{host: "myns.servicebus.usgovcloudapi.net"},
+		},
+		{
+			connectionString: "SharedAccessKeyName=RootManageSharedAccessKey;SharedAccessKey=abc=",
+			wantErr:          true,
+		},
+		{
+			connectionString: "",
+			wantErr:          true,
+		},
+	}
+
+	for _, tt := range tests {
+		got, err := parseConnectionString(tt.connectionString)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("parseConnectionString(%q): expected an error, got %+v", tt.connectionString, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parseConnectionString(%q): %v", tt.connectionString, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("parseConnectionString(%q) = %+v, want %+v", tt.connectionString, got, tt.want)
+		}
+	}
+}
+
+func TestNamespaceName(t *testing.T) {
+	tests := []struct {
+		props connectionStringProperties
+		want  string
+	}{
+		{connectionStringProperties{host: "myns.servicebus.windows.net"}, "myns"},
+		{connectionStringProperties{host: "MyNs.ServiceBus.Windows.Net"}, "myns"},
+		{connectionStringProperties{host: "myns.servicebus.usgovcloudapi.net"}, "myns"},
+		{connectionStringProperties{host: "myns.servicebus.chinacloudapi.cn"}, "myns"},
+		{connectionStringProperties{host: "localhost", emulator: true}, "localhost"},
+		{connectionStringProperties{host: "localhost", port: "5672", emulator: true}, "localhost:5672"},
+		{connectionStringProperties{host: "sb.internal.example.com"}, "sb.internal.example.com"},
+		{connectionStringProperties{host: "a.b.servicebus.windows.net"}, "a.b.servicebus.windows.net"},
+		{connectionStringProperties{host: "::1", port: "5672"}, "[::1]:5672"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.props.namespaceName(); got != tt.want {
+			t.Errorf("%+v.namespaceName() = %q, want %q", tt.props, got, tt.want)
+		}
+	}
+}
+
+func TestNamespaceHost(t *testing.T) {
+	tests := []struct {
+		namespace string
+		want      string
+	}{
+		{"myns", "myns.servicebus.windows.net"},
+		{"myns.servicebus.windows.net", "myns.servicebus.windows.net"},
+		{"myns.servicebus.usgovcloudapi.net", "myns.servicebus.usgovcloudapi.net"},
+		{"localhost", "localhost"},
+		{"localhost:5672", "localhost:5672"},
+		{"sb.internal.example.com", "sb.internal.example.com"},
+	}
+
+	for _, tt := range tests {
+		if got := namespaceHost(tt.namespace); got != tt.want {
+			t.Errorf("namespaceHost(%q) = %q, want %q", tt.namespace, got, tt.want)
+		}
+	}
+}
+
+func TestAdminConnectionString(t *testing.T) {
+	tests := []struct {
+		connectionString string
+		want             string
+	}{
+		{
+			connectionString: "Endpoint=sb://myns.servicebus.windows.net/;SharedAccessKeyName=a;SharedAccessKey=b",
+			want:             "Endpoint=sb://myns.servicebus.windows.net/;SharedAccessKeyName=a;SharedAccessKey=b",
+		},
+		{
+			connectionString: "Endpoint=sb://localhost;SharedAccessKeyName=a;SharedAccessKey=b;UseDevelopmentEmulator=true;",
+			want:             "Endpoint=sb://localhost:5300;SharedAccessKeyName=a;SharedAccessKey=b;UseDevelopmentEmulator=true;",
+		},
+		{
+			connectionString: "Endpoint=sb://localhost:5672;SharedAccessKeyName=a;SharedAccessKey=b;UseDevelopmentEmulator=true",
+			want:             "Endpoint=sb://localhost:5300;SharedAccessKeyName=a;SharedAccessKey=b;UseDevelopmentEmulator=true",
+		},
+	}
+
+	for _, tt := range tests {
+		props, err := parseConnectionString(tt.connectionString)
+		if err != nil {
+			t.Fatalf("parseConnectionString(%q): %v", tt.connectionString, err)
+		}
+		if got := props.adminConnectionString(tt.connectionString); got != tt.want {
+			t.Errorf("adminConnectionString(%q) = %q, want %q", tt.connectionString, got, tt.want)
+		}
+	}
+}
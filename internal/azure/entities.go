@@ -0,0 +1,184 @@
+package azure
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/messaging/azservicebus/admin"
+)
+
+// EntityProperties describes a queue, topic or subscription. Properties that don't apply to the
+// entity type are left nil.
+type EntityProperties struct {
+	EntityName                          string
+	Status                              string
+	MaxSizeInMegabytes                  *int32
+	DefaultMessageTimeToLive            *string // ISO 8601 duration, see ParseISODuration
+	LockDuration                        *string
+	MaxDeliveryCount                    *int32
+	RequiresDuplicateDetection          *bool
+	DuplicateDetectionHistoryTimeWindow *string
+	RequiresSession                     *bool
+	EnablePartitioning                  *bool
+	ForwardTo                           *string // an empty target turns forwarding off on update
+	ForwardDeadLetteredMessagesTo       *string
+	SizeInBytes                         *int64
+	CreatedAt                           time.Time
+	UpdatedAt                           time.Time
+	AccessedAt                          time.Time
+}
+
+func (sbc *ServiceBusClient) GetQueueProperties(ctx context.Context, queueName string) (*EntityProperties, error) {
+	queue, err := sbc.adminClient.GetQueue(ctx, queueName, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get queue %s: %w", queueName, err)
+	}
+	if queue == nil {
+		return nil, fmt.Errorf("queue %s not found", queueName)
+	}
+
+	runtime, err := sbc.adminClient.GetQueueRuntimeProperties(ctx, queueName, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get runtime properties of queue %s: %w", queueName, err)
+	}
+
+	props := &EntityProperties{
+		EntityName:                          queueName,
+		Status:                              entityStatus(queue.Status),
+		MaxSizeInMegabytes:                  queue.MaxSizeInMegabytes,
+		DefaultMessageTimeToLive:            queue.DefaultMessageTimeToLive,
+		LockDuration:                        queue.LockDuration,
+		MaxDeliveryCount:                    queue.MaxDeliveryCount,
+		RequiresDuplicateDetection:          queue.RequiresDuplicateDetection,
+		DuplicateDetectionHistoryTimeWindow: queue.DuplicateDetectionHistoryTimeWindow,
+		RequiresSession:                     queue.RequiresSession,
+		EnablePartitioning:                  queue.EnablePartitioning,
+		ForwardTo:                           queue.ForwardTo,
+		ForwardDeadLetteredMessagesTo:       queue.ForwardDeadLetteredMessagesTo,
+	}
+	if runtime != nil {
+		props.SizeInBytes = &runtime.SizeInBytes
+		props.CreatedAt = runtime.CreatedAt
+		props.UpdatedAt = runtime.UpdatedAt
+		props.AccessedAt = runtime.AccessedAt
+	}
+
+	return props, nil
+}
+
+func (sbc *ServiceBusClient) GetTopicProperties(ctx context.Context, topicName string) (*EntityProperties, error) {
+	topic, err := sbc.adminClient.GetTopic(ctx, topicName, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get topic %s: %w", topicName, err)
+	}
+	if topic == nil {
+		return nil, fmt.Errorf("topic %s not found", topicName)
+	}
+
+	runtime, err := sbc.adminClient.GetTopicRuntimeProperties(ctx, topicName, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get runtime properties of topic %s: %w", topicName, err)
+	}
+
+	props := &EntityProperties{
+		EntityName:                          topicName,
+		Status:                              entityStatus(topic.Status),
+		MaxSizeInMegabytes:                  topic.MaxSizeInMegabytes,
+		DefaultMessageTimeToLive:            topic.DefaultMessageTimeToLive,
+		RequiresDuplicateDetection:          topic.RequiresDuplicateDetection,
+		DuplicateDetectionHistoryTimeWindow: topic.DuplicateDetectionHistoryTimeWindow,
+		EnablePartitioning:                  topic.EnablePartitioning,
+	}
+	if runtime != nil {
+		props.SizeInBytes = &runtime.SizeInBytes
+		props.CreatedAt = runtime.CreatedAt
+		props.UpdatedAt = runtime.UpdatedAt
+		props.AccessedAt = runtime.AccessedAt
+	}
+
+	return props, nil
+}
+
+// GetSubscriptionProperties returns the properties of entityName, formatted as "topic/subscription".
+func (sbc *ServiceBusClient) GetSubscriptionProperties(ctx context.Context, entityName string) (*EntityProperties, error) {
+	topicName, subscriptionName, err := splitSubscriptionName(entityName)
+	if err != nil {
+		return nil, err
+	}
+
+	sub, err := sbc.adminClient.GetSubscription(ctx, topicName, subscriptionName, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get subscription %s: %w", entityName, err)
+	}
+	if sub == nil {
+		return nil, fmt.Errorf("subscription %s not found", entityName)
+	}
+
+	runtime, err := sbc.adminClient.GetSubscriptionRuntimeProperties(ctx, topicName, subscriptionName, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get runtime properties of subscription %s: %w", entityName, err)
+	}
+
+	props := &EntityProperties{
+		EntityName:                    entityName,
+		Status:                        entityStatus(sub.Status),
+		DefaultMessageTimeToLive:      sub.DefaultMessageTimeToLive,
+		LockDuration:                  sub.LockDuration,
+		MaxDeliveryCount:              sub.MaxDeliveryCount,
+		RequiresSession:               sub.RequiresSession,
+		ForwardTo:                     sub.ForwardTo,
+		ForwardDeadLetteredMessagesTo: sub.ForwardDeadLetteredMessagesTo,
+	}
+	if runtime != nil {
+		props.CreatedAt = runtime.CreatedAt
+		props.UpdatedAt = runtime.UpdatedAt
+		props.AccessedAt = runtime.AccessedAt
+	}
+
+	return props, nil
+}
+
+func entityStatus(status *admin.EntityStatus) string {
+	if status == nil {
+		return ""
+	}
+	return string(*status)
+}
+
+// NeverExpires is the ISO 8601 duration the service uses for "no expiry", e.g. as the default message TTL.
+const NeverExpires = "P10675199DT2H48M5.4775807S"
+
+var isoDurationPattern = regexp.MustCompile(`^P(?:(\d+)D)?(?:T(?:(\d+)H)?(?:(\d+)M)?(?:(\d+(?:\.\d+)?)S)?)?$`)
+
+// ParseISODuration parses the ISO 8601 durations used by the admin API, e.g. "PT1M" or "P14D".
+// Durations beyond time.Duration's range, such as NeverExpires, are capped.
+func ParseISODuration(s string) (time.Duration, error) {
+	m := isoDurationPattern.FindStringSubmatch(s)
+	if m == nil || s == "P" || s == "PT" {
+		return 0, fmt.Errorf("invalid ISO 8601 duration: %q", s)
+	}
+
+	var seconds float64
+	units := []float64{24 * 3600, 3600, 60, 1}
+	for i, unit := range units {
+		if m[i+1] == "" {
+			continue
+		}
+		v, err := strconv.ParseFloat(m[i+1], 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid ISO 8601 duration: %q", s)
+		}
+		seconds += v * unit
+	}
+
+	if seconds >= float64(maxDuration/time.Second) {
+		return maxDuration, nil
+	}
+	return time.Duration(seconds * float64(time.Second)), nil
+}
+
+const maxDuration = time.Duration(math.MaxInt64)
@@ -0,0 +1,175 @@
+package azure
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/MonsieurTib/service-bus-tui/internal/sqlfilter"
+)
+
+// SystemProperty returns the system property that SQL filters reference as sys.<name>, e.g. sys.Label.
+// Names are case-insensitive; ok is false when the message doesn't carry the property.
+func (m MessageInfo) SystemProperty(name string) (any, bool) {
+	switch strings.ToLower(name) {
+	case "messageid":
+		return m.MessageID, m.MessageID != ""
+	case "sequencenumber":
+		return m.SequenceNumber, true
+	case "label", "subject":
+		return m.Subject, m.Subject != ""
+	case "contenttype":
+		return m.ContentType, m.ContentType != ""
+	case "correlationid":
+		return m.CorrelationID, m.CorrelationID != ""
+	case "sessionid":
+		return m.SessionID, m.SessionID != ""
+	case "replyto":
+		return m.ReplyTo, m.ReplyTo != ""
+	case "replytosessionid":
+		return m.ReplyToSessionID, m.ReplyToSessionID != ""
+	case "to":
+		return m.To, m.To != ""
+	case "partitionkey":
+		return m.PartitionKey, m.PartitionKey != ""
+	case "deliverycount":
+		return m.DeliveryCount, true
+	case "state":
+		return m.State, m.State != ""
+	case "deadlettersource":
+		return m.DeadLetterSource, m.DeadLetterSource != ""
+	case "enqueuedtimeutc":
+		return m.EnqueuedTime.UTC(), !m.EnqueuedTime.IsZero()
+	case "scheduledenqueuetimeutc":
+		return m.ScheduledEnqueueTime.UTC(), !m.ScheduledEnqueueTime.IsZero()
+	case "expiresatutc":
+		return m.ExpiresAt.UTC(), !m.ExpiresAt.IsZero()
+	case "lockeduntilutc":
+		return m.LockedUntil.UTC(), !m.LockedUntil.IsZero()
+	case "timetolive":
+		return m.TimeToLive, m.TimeToLive > 0
+	}
+	return nil, false
+}
+
+// UserProperty returns the application property that SQL filters reference as user.<name> or <name>.
+func (m MessageInfo) UserProperty(name string) (any, bool) {
+	v, ok := m.Properties[name]
+	return v, ok
+}
+
+// RuleMatcher reports whether a message would be accepted by a rule's filter.
+type RuleMatcher func(msg MessageInfo) (bool, error)
+
+// NewRuleMatcher evaluates the filter of rule locally, without calling the service.
+func NewRuleMatcher(rule RuleInfo) (RuleMatcher, error) {
+	switch rule.FilterType {
+	case RuleFilterSQL:
+		f, err := sqlfilter.ParseWithParameters(rule.SQLExpression, rule.SQLParameters)
+		if err != nil {
+			return nil, fmt.Errorf("invalid SQL filter of rule %s: %w", rule.Name, err)
+		}
+		return func(msg MessageInfo) (bool, error) { return f.Match(msg) }, nil
+
+	case RuleFilterCorrelation:
+		c := rule.Correlation
+		return func(msg MessageInfo) (bool, error) { return c.matches(msg), nil }, nil
+
+	case RuleFilterTrue:
+		return func(MessageInfo) (bool, error) { return true, nil }, nil
+
+	case RuleFilterFalse:
+		return func(MessageInfo) (bool, error) { return false, nil }, nil
+	}
+
+	return nil, fmt.Errorf("filter type %q of rule %s can't be evaluated locally", rule.FilterType, rule.Name)
+}
+
+// matches reports whether every field set on the filter equals the corresponding property of msg.
+func (c CorrelationFilter) matches(msg MessageInfo) bool {
+	system := []struct{ name, want string }{
+		{"CorrelationId", c.CorrelationID},
+		{"MessageId", c.MessageID},
+		{"To", c.To},
+		{"ReplyTo", c.ReplyTo},
+		{"Label", c.Subject},
+		{"SessionId", c.SessionID},
+		{"ReplyToSessionId", c.ReplyToSessionID},
+		{"ContentType", c.ContentType},
+	}
+	for _, p := range system {
+		if p.want == "" {
+			continue
+		}
+		if v, ok := msg.SystemProperty(p.name); !ok || fmt.Sprint(v) != p.want {
+			return false
+		}
+	}
+
+	for name, want := range c.Properties {
+		if v, ok := msg.UserProperty(name); !ok || !propertyValuesEqual(v, want) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// propertyValuesEqual compares application property values by type: numbers of any width are equal when their
+// values are, e.g. an int32 property and the int64 of a filter, but the string "123" is not the number 123.
+func propertyValuesEqual(a, b any) bool {
+	if x, ok := asInt64(a); ok {
+		if y, ok := asInt64(b); ok {
+			return x == y
+		}
+	}
+	if x, ok := asFloat64(a); ok {
+		if y, ok := asFloat64(b); ok {
+			return x == y
+		}
+	}
+	if x, ok := a.(time.Time); ok {
+		y, ok := b.(time.Time)
+		return ok && x.Equal(y)
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+func asInt64(v any) (int64, bool) {
+	switch v := v.(type) {
+	case int:
+		return int64(v), true
+	case int8:
+		return int64(v), true
+	case int16:
+		return int64(v), true
+	case int32:
+		return int64(v), true
+	case int64:
+		return v, true
+	case uint8:
+		return int64(v), true
+	case uint16:
+		return int64(v), true
+	case uint32:
+		return int64(v), true
+	case uint64:
+		return int64(v), v <= math.MaxInt64
+	}
+	return 0, false
+}
+
+func asFloat64(v any) (float64, bool) {
+	switch v := v.(type) {
+	case float32:
+		return float64(v), true
+	case float64:
+		return v, true
+	}
+	if i, ok := asInt64(v); ok {
+		return float64(i), true
+	}
+	return 0, false
+}
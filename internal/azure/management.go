@@ -0,0 +1,232 @@
+package azure
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/messaging/azservicebus/admin"
+)
+
+// The Create* and Update* methods take the settings to apply as EntityProperties. Nil fields keep the
+// service defaults on create and the current value on update; read-only fields such as timestamps are ignored.
+
+func (sbc *ServiceBusClient) CreateQueue(ctx context.Context, queueName string, props EntityProperties) error {
+	var queue admin.QueueProperties
+	applyQueueProperties(&queue, props)
+
+	if _, err := sbc.adminClient.CreateQueue(ctx, queueName, &admin.CreateQueueOptions{Properties: &queue}); err != nil {
+		return fmt.Errorf("failed to create queue %s: %w", queueName, err)
+	}
+	return nil
+}
+
+func (sbc *ServiceBusClient) UpdateQueue(ctx context.Context, queueName string, props EntityProperties) error {
+	current, err := sbc.adminClient.GetQueue(ctx, queueName, nil)
+	if err != nil {
+		return fmt.Errorf("failed to get queue %s: %w", queueName, err)
+	}
+	if current == nil {
+		return fmt.Errorf("queue %s not found", queueName)
+	}
+
+	queue := current.QueueProperties
+	applyQueueProperties(&queue, props)
+
+	if _, err := sbc.adminClient.UpdateQueue(ctx, queueName, queue, nil); err != nil {
+		return fmt.Errorf("failed to update queue %s: %w", queueName, err)
+	}
+	return nil
+}
+
+func (sbc *ServiceBusClient) DeleteQueue(ctx context.Context, queueName string) error {
+	if _, err := sbc.adminClient.DeleteQueue(ctx, queueName, nil); err != nil {
+		return fmt.Errorf("failed to delete queue %s: %w", queueName, err)
+	}
+	return nil
+}
+
+func (sbc *ServiceBusClient) CreateTopic(ctx context.Context, topicName string, props EntityProperties) error {
+	var topic admin.TopicProperties
+	applyTopicProperties(&topic, props)
+
+	if _, err := sbc.adminClient.CreateTopic(ctx, topicName, &admin.CreateTopicOptions{Properties: &topic}); err != nil {
+		return fmt.Errorf("failed to create topic %s: %w", topicName, err)
+	}
+	return nil
+}
+
+func (sbc *ServiceBusClient) UpdateTopic(ctx context.Context, topicName string, props EntityProperties) error {
+	current, err := sbc.adminClient.GetTopic(ctx, topicName, nil)
+	if err != nil {
+		return fmt.Errorf("failed to get topic %s: %w", topicName, err)
+	}
+	if current == nil {
+		return fmt.Errorf("topic %s not found", topicName)
+	}
+
+	topic := current.TopicProperties
+	applyTopicProperties(&topic, props)
+
+	if _, err := sbc.adminClient.UpdateTopic(ctx, topicName, topic, nil); err != nil {
+		return fmt.Errorf("failed to update topic %s: %w", topicName, err)
+	}
+	return nil
+}
+
+func (sbc *ServiceBusClient) DeleteTopic(ctx context.Context, topicName string) error {
+	if _, err := sbc.adminClient.DeleteTopic(ctx, topicName, nil); err != nil {
+		return fmt.Errorf("failed to delete topic %s: %w", topicName, err)
+	}
+	return nil
+}
+
+// CreateSubscription creates entityName, formatted as "topic/subscription".
+func (sbc *ServiceBusClient) CreateSubscription(ctx context.Context, entityName string, props EntityProperties) error {
+	topicName, subscriptionName, err := splitSubscriptionName(entityName)
+	if err != nil {
+		return err
+	}
+
+	var sub admin.SubscriptionProperties
+	applySubscriptionProperties(&sub, props)
+
+	opts := &admin.CreateSubscriptionOptions{Properties: &sub}
+	if _, err := sbc.adminClient.CreateSubscription(ctx, topicName, subscriptionName, opts); err != nil {
+		return fmt.Errorf("failed to create subscription %s: %w", entityName, err)
+	}
+	return nil
+}
+
+func (sbc *ServiceBusClient) UpdateSubscription(ctx context.Context, entityName string, props EntityProperties) error {
+	topicName, subscriptionName, err := splitSubscriptionName(entityName)
+	if err != nil {
+		return err
+	}
+
+	current, err := sbc.adminClient.GetSubscription(ctx, topicName, subscriptionName, nil)
+	if err != nil {
+		return fmt.Errorf("failed to get subscription %s: %w", entityName, err)
+	}
+	if current == nil {
+		return fmt.Errorf("subscription %s not found", entityName)
+	}
+
+	sub := current.SubscriptionProperties
+	applySubscriptionProperties(&sub, props)
+
+	if _, err := sbc.adminClient.UpdateSubscription(ctx, topicName, subscriptionName, sub, nil); err != nil {
+		return fmt.Errorf("failed to update subscription %s: %w", entityName, err)
+	}
+	return nil
+}
+
+func (sbc *ServiceBusClient) DeleteSubscription(ctx context.Context, entityName string) error {
+	topicName, subscriptionName, err := splitSubscriptionName(entityName)
+	if err != nil {
+		return err
+	}
+
+	if _, err := sbc.adminClient.DeleteSubscription(ctx, topicName, subscriptionName, nil); err != nil {
+		return fmt.Errorf("failed to delete subscription %s: %w", entityName, err)
+	}
+	return nil
+}
+
+func splitSubscriptionName(entityName string) (topicName, subscriptionName string, err error) {
+	topicName, subscriptionName, ok := strings.Cut(entityName, "/")
+	if !ok || topicName == "" || subscriptionName == "" {
+		return "", "", fmt.Errorf("invalid subscription name %q, expected topic/subscription", entityName)
+	}
+	return topicName, subscriptionName, nil
+}
+
+func applyQueueProperties(queue *admin.QueueProperties, props EntityProperties) {
+	setIfNotNil(&queue.MaxSizeInMegabytes, props.MaxSizeInMegabytes)
+	setIfNotNil(&queue.DefaultMessageTimeToLive, props.DefaultMessageTimeToLive)
+	setIfNotNil(&queue.LockDuration, props.LockDuration)
+	setIfNotNil(&queue.MaxDeliveryCount, props.MaxDeliveryCount)
+	setIfNotNil(&queue.RequiresDuplicateDetection, props.RequiresDuplicateDetection)
+	setIfNotNil(&queue.DuplicateDetectionHistoryTimeWindow, props.DuplicateDetectionHistoryTimeWindow)
+	setIfNotNil(&queue.RequiresSession, props.RequiresSession)
+	setIfNotNil(&queue.EnablePartitioning, props.EnablePartitioning)
+	setForwarding(&queue.ForwardTo, props.ForwardTo)
+	setForwarding(&queue.ForwardDeadLetteredMessagesTo, props.ForwardDeadLetteredMessagesTo)
+	if props.Status != "" {
+		status := admin.EntityStatus(props.Status)
+		queue.Status = &status
+	}
+}
+
+func applyTopicProperties(topic *admin.TopicProperties, props EntityProperties) {
+	setIfNotNil(&topic.MaxSizeInMegabytes, props.MaxSizeInMegabytes)
+	setIfNotNil(&topic.DefaultMessageTimeToLive, props.DefaultMessageTimeToLive)
+	setIfNotNil(&topic.RequiresDuplicateDetection, props.RequiresDuplicateDetection)
+	setIfNotNil(&topic.DuplicateDetectionHistoryTimeWindow, props.DuplicateDetectionHistoryTimeWindow)
+	setIfNotNil(&topic.EnablePartitioning, props.EnablePartitioning)
+	if props.Status != "" {
+		status := admin.EntityStatus(props.Status)
+		topic.Status = &status
+	}
+}
+
+func applySubscriptionProperties(sub *admin.SubscriptionProperties, props EntityProperties) {
+	setIfNotNil(&sub.DefaultMessageTimeToLive, props.DefaultMessageTimeToLive)
+	setIfNotNil(&sub.LockDuration, props.LockDuration)
+	setIfNotNil(&sub.MaxDeliveryCount, props.MaxDeliveryCount)
+	setIfNotNil(&sub.RequiresSession, props.RequiresSession)
+	setForwarding(&sub.ForwardTo, props.ForwardTo)
+	setForwarding(&sub.ForwardDeadLetteredMessagesTo, props.ForwardDeadLetteredMessagesTo)
+	if props.Status != "" {
+		status := admin.EntityStatus(props.Status)
+		sub.Status = &status
+	}
+}
+
+func setIfNotNil[T any](dst **T, src *T) {
+	if src != nil {
+		*dst = src
+	}
+}
+
+// setForwarding is setIfNotNil for forwarding targets, where an empty target turns forwarding off.
+func setForwarding(dst **string, src *string) {
+	switch {
+	case src == nil:
+	case *src == "":
+		*dst = nil
+	default:
+		*dst = src
+	}
+}
+
+// FormatISODuration is the inverse of ParseISODuration, e.g. 90*time.Second becomes "PT1M30S".
+func FormatISODuration(d time.Duration) string {
+	days := d / (24 * time.Hour)
+	d -= days * 24 * time.Hour
+	hours := d / time.Hour
+	d -= hours * time.Hour
+	minutes := d / time.Minute
+	d -= minutes * time.Minute
+	seconds := d.Seconds()
+
+	var b strings.Builder
+	b.WriteString("P")
+	if days > 0 {
+		fmt.Fprintf(&b, "%dD", days)
+	}
+	if hours > 0 || minutes > 0 || seconds > 0 || days == 0 {
+		b.WriteString("T")
+		if hours > 0 {
+			fmt.Fprintf(&b, "%dH", hours)
+		}
+		if minutes > 0 {
+			fmt.Fprintf(&b, "%dM", minutes)
+		}
+		if seconds > 0 || (days == 0 && hours == 0 && minutes == 0) {
+			fmt.Fprintf(&b, "%gS", seconds)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,287 @@
+package azure
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/messaging/azservicebus"
+)
+
+const (
+	receiveBatchSize    = 50
+	receiveBatchTimeout = 5 * time.Second
+	settleTimeout       = 10 * time.Second
+	maxScannedMessages  = 5000
+	purgeBatchSize      = 250
+)
+
+// MessageResult reports the outcome of an operation on a single message.
+type MessageResult struct {
+	SequenceNumber int64
+	Err            error
+}
+
+var errMessageNotFound = errors.New("message not found")
+
+// SendMessage sends msg to the queue or topic named entityName. Empty fields of msg are left unset.
+func (sbc *ServiceBusClient) SendMessage(ctx context.Context, entityName string, msg MessageInfo) error {
+	sender, err := sbc.client.NewSender(entityName, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create sender: %w", err)
+	}
+	defer sender.Close(ctx)
+
+	if err := sender.SendMessage(ctx, newMessage(msg), nil); err != nil {
+		return fmt.Errorf("failed to send message: %w", err)
+	}
+	return nil
+}
+
+// newMessage converts msg into a message ready to be sent.
+func newMessage(msg MessageInfo) *azservicebus.Message {
+	out := &azservicebus.Message{
+		Body:                  []byte(msg.Body),
+		ApplicationProperties: maps.Clone(msg.Properties),
+	}
+
+	if msg.MessageID != "" {
+		out.MessageID = &msg.MessageID
+	}
+	if msg.Subject != "" {
+		out.Subject = &msg.Subject
+	}
+	if msg.ContentType != "" {
+		out.ContentType = &msg.ContentType
+	}
+	if msg.CorrelationID != "" {
+		out.CorrelationID = &msg.CorrelationID
+	}
+	if msg.SessionID != "" {
+		out.SessionID = &msg.SessionID
+	}
+	if msg.ReplyTo != "" {
+		out.ReplyTo = &msg.ReplyTo
+	}
+	if msg.ReplyToSessionID != "" {
+		out.ReplyToSessionID = &msg.ReplyToSessionID
+	}
+	if msg.To != "" {
+		out.To = &msg.To
+	}
+	if msg.PartitionKey != "" {
+		out.PartitionKey = &msg.PartitionKey
+	}
+	if msg.TimeToLive > 0 {
+		out.TimeToLive = &msg.TimeToLive
+	}
+	if !msg.ScheduledEnqueueTime.IsZero() {
+		out.ScheduledEnqueueTime = &msg.ScheduledEnqueueTime
+	}
+
+	return out
+}
+
+// ResubmitDeadLetterMessages moves the given messages from the dead-letter subqueue of entityName back
+// to its parent queue or topic. Each message is sent as a copy and the original is then completed.
+//
+// Copies keep the MessageID of the original, so when the parent has duplicate detection, a copy sent within its
+// detection window is dropped by the broker while the original is still completed: check
+// ParentRequiresDuplicateDetection first.
+func (sbc *ServiceBusClient) ResubmitDeadLetterMessages(ctx context.Context, entityName string, sequenceNumbers []int64) ([]MessageResult, error) {
+	sender, err := sbc.client.NewSender(ParentEntityName(entityName), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create sender: %w", err)
+	}
+	defer sender.Close(ctx)
+
+	return sbc.settleMessages(ctx, entityName, true, sequenceNumbers, func(ctx context.Context, msg *azservicebus.ReceivedMessage) error {
+		if err := sender.SendMessage(ctx, resubmitCopy(msg), nil); err != nil {
+			return fmt.Errorf("failed to send copy: %w", err)
+		}
+		return nil
+	})
+}
+
+// ParentRequiresDuplicateDetection reports whether the queue or topic that messages of entityName are resubmitted
+// to drops messages whose MessageID it has already seen within its duplicate detection window.
+func (sbc *ServiceBusClient) ParentRequiresDuplicateDetection(ctx context.Context, entityName string) (bool, error) {
+	var props *EntityProperties
+	var err error
+	if parent := ParentEntityName(entityName); parent != entityName {
+		props, err = sbc.GetTopicProperties(ctx, parent)
+	} else {
+		props, err = sbc.GetQueueProperties(ctx, parent)
+	}
+	if err != nil {
+		return false, err
+	}
+	return props.RequiresDuplicateDetection != nil && *props.RequiresDuplicateDetection, nil
+}
+
+// DeleteMessages receives the given messages from entityName, or from its dead-letter subqueue when
+// isDeadLetter is set, and completes them. Other messages received along the way are abandoned.
+func (sbc *ServiceBusClient) DeleteMessages(ctx context.Context, entityName string, isDeadLetter bool, sequenceNumbers []int64) ([]MessageResult, error) {
+	return sbc.settleMessages(ctx, entityName, isDeadLetter, sequenceNumbers, nil)
+}
+
+// PurgeMessages drains entityName, or its dead-letter subqueue when isDeadLetter is set, in receive-and-delete
+// mode until a batch comes back empty or ctx is cancelled. progress is called with the running total after
+// each batch. It returns the number of messages deleted, which is also meaningful when an error is returned.
+func (sbc *ServiceBusClient) PurgeMessages(ctx context.Context, entityName string, isDeadLetter bool, progress func(purged int)) (int, error) {
+	receiver, err := sbc.newReceiver(entityName, isDeadLetter, &azservicebus.ReceiverOptions{
+		ReceiveMode: azservicebus.ReceiveModeReceiveAndDelete,
+	})
+	if err != nil {
+		return 0, err
+	}
+	defer receiver.Close(context.Background())
+
+	purged := 0
+	for {
+		batchCtx, cancel := context.WithTimeout(ctx, receiveBatchTimeout)
+		messages, err := receiver.ReceiveMessages(batchCtx, purgeBatchSize, nil)
+		cancel()
+
+		// Received messages are already deleted, even when the batch ends with an error.
+		purged += len(messages)
+		if len(messages) > 0 && progress != nil {
+			progress(purged)
+		}
+
+		if ctx.Err() != nil {
+			return purged, ctx.Err()
+		}
+		if err != nil && !errors.Is(err, context.DeadlineExceeded) {
+			return purged, fmt.Errorf("failed to receive messages: %w", err)
+		}
+		if len(messages) == 0 {
+			return purged, nil
+		}
+	}
+}
+
+// ParentEntityName returns the queue or topic a message is sent to for entityName.
+func ParentEntityName(entityName string) string {
+	topicName, _, _ := strings.Cut(entityName, "/")
+	return topicName
+}
+
+// resubmitCopy copies a dead-lettered message, dropping the properties the broker added when it was dead-lettered.
+func resubmitCopy(msg *azservicebus.ReceivedMessage) *azservicebus.Message {
+	cp := msg.Message()
+	cp.ScheduledEnqueueTime = nil
+
+	if msg.ApplicationProperties != nil {
+		cp.ApplicationProperties = maps.Clone(msg.ApplicationProperties)
+		delete(cp.ApplicationProperties, "DeadLetterReason")
+		delete(cp.ApplicationProperties, "DeadLetterErrorDescription")
+	}
+
+	return cp
+}
+
+// settleMessages receives messages from entityName in peek-lock mode until every target sequence number has
+// been found. For each target, handle is called and the message is completed if it succeeds or abandoned
+// otherwise. A nil handle just completes the targets. Other messages are kept locked until the scan is over
+// and then abandoned, so they are not received twice.
+//
+// When receiving fails, the results of the targets already settled are returned along with the error, and the
+// remaining targets are reported as not processed.
+func (sbc *ServiceBusClient) settleMessages(
+	ctx context.Context,
+	entityName string,
+	isDeadLetter bool,
+	sequenceNumbers []int64,
+	handle func(context.Context, *azservicebus.ReceivedMessage) error,
+) ([]MessageResult, error) {
+	receiver, err := sbc.newReceiver(entityName, isDeadLetter, &azservicebus.ReceiverOptions{
+		ReceiveMode: azservicebus.ReceiveModePeekLock,
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer receiver.Close(context.Background())
+
+	pending := make(map[int64]bool, len(sequenceNumbers))
+	for _, seq := range sequenceNumbers {
+		pending[seq] = true
+	}
+	outcomes := make(map[int64]error, len(sequenceNumbers))
+
+	var held []*azservicebus.ReceivedMessage
+	defer func() {
+		for _, msg := range held {
+			abandonCtx, cancel := context.WithTimeout(context.Background(), settleTimeout)
+			_ = receiver.AbandonMessage(abandonCtx, msg, nil)
+			cancel()
+		}
+	}()
+
+	var receiveErr error
+	scanned := 0
+	for len(pending) > 0 && scanned < maxScannedMessages {
+		batchCtx, cancel := context.WithTimeout(ctx, receiveBatchTimeout)
+		messages, err := receiver.ReceiveMessages(batchCtx, receiveBatchSize, nil)
+		cancel()
+
+		if err != nil && !errors.Is(err, context.DeadlineExceeded) {
+			receiveErr = fmt.Errorf("failed to receive messages: %w", err)
+			break
+		}
+		if len(messages) == 0 {
+			break
+		}
+		scanned += len(messages)
+
+		for _, msg := range messages {
+			if msg.SequenceNumber == nil || !pending[*msg.SequenceNumber] {
+				held = append(held, msg)
+				continue
+			}
+
+			seq := *msg.SequenceNumber
+			delete(pending, seq)
+			outcomes[seq] = settleMessage(ctx, receiver, msg, handle)
+		}
+	}
+
+	results := make([]MessageResult, 0, len(sequenceNumbers))
+	for _, seq := range sequenceNumbers {
+		outcome, found := outcomes[seq]
+		switch {
+		case found:
+		case receiveErr != nil:
+			outcome = fmt.Errorf("not processed: %w", receiveErr)
+		default:
+			outcome = errMessageNotFound
+		}
+		results = append(results, MessageResult{SequenceNumber: seq, Err: outcome})
+	}
+
+	return results, receiveErr
+}
+
+func settleMessage(
+	ctx context.Context,
+	receiver *azservicebus.Receiver,
+	msg *azservicebus.ReceivedMessage,
+	handle func(context.Context, *azservicebus.ReceivedMessage) error,
+) error {
+	if handle != nil {
+		if err := handle(ctx, msg); err != nil {
+			abandonCtx, cancel := context.WithTimeout(context.Background(), settleTimeout)
+			defer cancel()
+			_ = receiver.AbandonMessage(abandonCtx, msg, nil)
+			return err
+		}
+	}
+
+	if err := receiver.CompleteMessage(ctx, msg, nil); err != nil {
+		return fmt.Errorf("failed to complete message: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,209 @@
+package azure
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/messaging/azservicebus/admin"
+)
+
+// Rule filter types, as shown in the tree.
+const (
+	RuleFilterSQL         = "SQL"
+	RuleFilterCorrelation = "Correlation"
+	RuleFilterTrue        = "True"
+	RuleFilterFalse       = "False"
+)
+
+// RuleInfo describes a subscription rule: the filter messages must match, and the optional SQL action applied to
+// the messages it accepts.
+type RuleInfo struct {
+	Name             string
+	FilterType       string // one of the RuleFilter* constants, or the raw type of filters this client doesn't know
+	SQLExpression    string
+	SQLParameters    map[string]any
+	Correlation      CorrelationFilter
+	ActionExpression string // "" when the rule has no action
+	ActionParameters map[string]any
+}
+
+// CorrelationFilter matches messages whose system and application properties equal every non-empty field.
+type CorrelationFilter struct {
+	CorrelationID    string
+	MessageID        string
+	To               string
+	ReplyTo          string
+	Subject          string
+	SessionID        string
+	ReplyToSessionID string
+	ContentType      string
+	Properties       map[string]any
+}
+
+// IsEmpty reports whether the filter has no fields to match, which the service rejects.
+func (c CorrelationFilter) IsEmpty() bool {
+	return c.CorrelationID == "" && c.MessageID == "" && c.To == "" && c.ReplyTo == "" && c.Subject == "" &&
+		c.SessionID == "" && c.ReplyToSessionID == "" && c.ContentType == "" && len(c.Properties) == 0
+}
+
+// ListRules returns the rules of entityName, formatted as "topic/subscription".
+func (sbc *ServiceBusClient) ListRules(ctx context.Context, entityName string) ([]RuleInfo, error) {
+	topicName, subscriptionName, err := splitSubscriptionName(entityName)
+	if err != nil {
+		return nil, err
+	}
+
+	var rules []RuleInfo
+	pager := sbc.adminClient.NewListRulesPager(topicName, subscriptionName, nil)
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list rules of %s: %w", entityName, err)
+		}
+		for _, rule := range page.Rules {
+			rules = append(rules, newRuleInfo(rule))
+		}
+	}
+
+	return rules, nil
+}
+
+func (sbc *ServiceBusClient) CreateRule(ctx context.Context, entityName string, rule RuleInfo) error {
+	topicName, subscriptionName, err := splitSubscriptionName(entityName)
+	if err != nil {
+		return err
+	}
+
+	filter, err := rule.adminFilter()
+	if err != nil {
+		return err
+	}
+
+	opts := &admin.CreateRuleOptions{
+		Name:   &rule.Name,
+		Filter: filter,
+		Action: rule.adminAction(),
+	}
+	if _, err := sbc.adminClient.CreateRule(ctx, topicName, subscriptionName, opts); err != nil {
+		return fmt.Errorf("failed to create rule %s on %s: %w", rule.Name, entityName, err)
+	}
+	return nil
+}
+
+// UpdateRule replaces the filter and action of the rule named rule.Name.
+func (sbc *ServiceBusClient) UpdateRule(ctx context.Context, entityName string, rule RuleInfo) error {
+	topicName, subscriptionName, err := splitSubscriptionName(entityName)
+	if err != nil {
+		return err
+	}
+
+	filter, err := rule.adminFilter()
+	if err != nil {
+		return err
+	}
+
+	props := admin.RuleProperties{
+		Name:   rule.Name,
+		Filter: filter,
+		Action: rule.adminAction(),
+	}
+	if _, err := sbc.adminClient.UpdateRule(ctx, topicName, subscriptionName, props); err != nil {
+		return fmt.Errorf("failed to update rule %s on %s: %w", rule.Name, entityName, err)
+	}
+	return nil
+}
+
+func (sbc *ServiceBusClient) DeleteRule(ctx context.Context, entityName, ruleName string) error {
+	topicName, subscriptionName, err := splitSubscriptionName(entityName)
+	if err != nil {
+		return err
+	}
+
+	if _, err := sbc.adminClient.DeleteRule(ctx, topicName, subscriptionName, ruleName, nil); err != nil {
+		return fmt.Errorf("failed to delete rule %s on %s: %w", ruleName, entityName, err)
+	}
+	return nil
+}
+
+func newRuleInfo(rule admin.RuleProperties) RuleInfo {
+	info := RuleInfo{Name: rule.Name}
+
+	switch f := rule.Filter.(type) {
+	case *admin.SQLFilter:
+		info.FilterType = RuleFilterSQL
+		info.SQLExpression = f.Expression
+		info.SQLParameters = f.Parameters
+	case *admin.CorrelationFilter:
+		info.FilterType = RuleFilterCorrelation
+		info.Correlation = CorrelationFilter{
+			CorrelationID:    derefString(f.CorrelationID),
+			MessageID:        derefString(f.MessageID),
+			To:               derefString(f.To),
+			ReplyTo:          derefString(f.ReplyTo),
+			Subject:          derefString(f.Subject),
+			SessionID:        derefString(f.SessionID),
+			ReplyToSessionID: derefString(f.ReplyToSessionID),
+			ContentType:      derefString(f.ContentType),
+			Properties:       f.ApplicationProperties,
+		}
+	case *admin.TrueFilter:
+		info.FilterType = RuleFilterTrue
+	case *admin.FalseFilter:
+		info.FilterType = RuleFilterFalse
+	case *admin.UnknownRuleFilter:
+		info.FilterType = f.Type
+	}
+
+	if a, ok := rule.Action.(*admin.SQLAction); ok {
+		info.ActionExpression = a.Expression
+		info.ActionParameters = a.Parameters
+	}
+
+	return info
+}
+
+func (r RuleInfo) adminFilter() (admin.RuleFilter, error) {
+	switch r.FilterType {
+	case RuleFilterSQL:
+		return &admin.SQLFilter{Expression: r.SQLExpression, Parameters: r.SQLParameters}, nil
+	case RuleFilterCorrelation:
+		c := r.Correlation
+		return &admin.CorrelationFilter{
+			CorrelationID:         nilIfEmpty(c.CorrelationID),
+			MessageID:             nilIfEmpty(c.MessageID),
+			To:                    nilIfEmpty(c.To),
+			ReplyTo:               nilIfEmpty(c.ReplyTo),
+			Subject:               nilIfEmpty(c.Subject),
+			SessionID:             nilIfEmpty(c.SessionID),
+			ReplyToSessionID:      nilIfEmpty(c.ReplyToSessionID),
+			ContentType:           nilIfEmpty(c.ContentType),
+			ApplicationProperties: c.Properties,
+		}, nil
+	case RuleFilterTrue:
+		return &admin.TrueFilter{}, nil
+	case RuleFilterFalse:
+		return &admin.FalseFilter{}, nil
+	}
+	return nil, fmt.Errorf("unsupported filter type %q", r.FilterType)
+}
+
+func (r RuleInfo) adminAction() admin.RuleAction {
+	if r.ActionExpression == "" {
+		return nil
+	}
+	return &admin.SQLAction{Expression: r.ActionExpression, Parameters: r.ActionParameters}
+}
+
+func derefString(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func nilIfEmpty(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
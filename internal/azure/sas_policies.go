@@ -0,0 +1,123 @@
+package azure
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/servicebus/armservicebus"
+)
+
+// SASPolicy is a shared access authorization rule of a namespace.
+type SASPolicy struct {
+	Name   string
+	Rights []string // Listen, Send and Manage
+}
+
+// SASKeys holds the keys of a SAS policy and the connection strings built from them.
+type SASKeys struct {
+	PrimaryKey                string
+	SecondaryKey              string
+	PrimaryConnectionString   string
+	SecondaryConnectionString string
+}
+
+// SetResource records the subscription and resource group of the namespace, when known from namespace discovery,
+// so ARM calls don't have to look them up.
+func (sbc *ServiceBusClient) SetResource(subscriptionID, resourceGroup string) {
+	sbc.armMu.Lock()
+	defer sbc.armMu.Unlock()
+	sbc.subscriptionID = subscriptionID
+	sbc.resourceGroup = resourceGroup
+}
+
+// HasResourceManager reports whether the namespace can be managed through Azure Resource Manager, which requires an
+// Entra ID credential: connection strings and the emulator have none.
+func (sbc *ServiceBusClient) HasResourceManager() bool {
+	return sbc.cred != nil && !sbc.emulator
+}
+
+// namespacesClient returns an ARM client for the subscription of the namespace, and its resource group. Both are
+// looked up through namespace discovery when they were not set with SetResource.
+func (sbc *ServiceBusClient) namespacesClient(ctx context.Context) (*armservicebus.NamespacesClient, string, error) {
+	if !sbc.HasResourceManager() {
+		return nil, "", fmt.Errorf("not available when connected with a connection string")
+	}
+
+	sbc.armMu.Lock()
+	defer sbc.armMu.Unlock()
+
+	if sbc.subscriptionID == "" || sbc.resourceGroup == "" {
+		namespaces, err := getNamespaces(ctx, sbc.cred)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to find namespace %s: %w", sbc.namespace, err)
+		}
+		for _, ns := range namespaces {
+			if ns.Name == sbc.namespace {
+				sbc.subscriptionID = ns.Subscription
+				sbc.resourceGroup = ns.ResourceGroup
+				break
+			}
+		}
+		if sbc.subscriptionID == "" {
+			return nil, "", fmt.Errorf("namespace %s not found in any accessible subscription", sbc.namespace)
+		}
+	}
+
+	client, err := armservicebus.NewNamespacesClient(sbc.subscriptionID, sbc.cred, armClientOptions())
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to create namespaces client: %w", err)
+	}
+	return client, sbc.resourceGroup, nil
+}
+
+// ListSASPolicies returns the shared access policies of the namespace.
+func (sbc *ServiceBusClient) ListSASPolicies(ctx context.Context) ([]SASPolicy, error) {
+	client, resourceGroup, err := sbc.namespacesClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var policies []SASPolicy
+	pager := client.NewListAuthorizationRulesPager(resourceGroup, sbc.namespace, nil)
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list SAS policies: %w", err)
+		}
+		for _, rule := range page.Value {
+			if rule == nil || rule.Name == nil {
+				continue
+			}
+			policy := SASPolicy{Name: *rule.Name}
+			if rule.Properties != nil {
+				for _, right := range rule.Properties.Rights {
+					if right != nil {
+						policy.Rights = append(policy.Rights, string(*right))
+					}
+				}
+			}
+			policies = append(policies, policy)
+		}
+	}
+	return policies, nil
+}
+
+// GetSASKeys fetches the keys of the SAS policy called policyName, which requires the listKeys permission on the
+// namespace.
+func (sbc *ServiceBusClient) GetSASKeys(ctx context.Context, policyName string) (SASKeys, error) {
+	client, resourceGroup, err := sbc.namespacesClient(ctx)
+	if err != nil {
+		return SASKeys{}, err
+	}
+
+	resp, err := client.ListKeys(ctx, resourceGroup, sbc.namespace, policyName, nil)
+	if err != nil {
+		return SASKeys{}, fmt.Errorf("failed to list keys of %s: %w", policyName, err)
+	}
+	return SASKeys{
+		PrimaryKey:                derefString(resp.PrimaryKey),
+		SecondaryKey:              derefString(resp.SecondaryKey),
+		PrimaryConnectionString:   derefString(resp.PrimaryConnectionString),
+		SecondaryConnectionString: derefString(resp.SecondaryConnectionString),
+	}, nil
+}
@@ -0,0 +1,272 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"filippo.io/age"
+	"github.com/zalando/go-keyring"
+)
+
+const (
+	// StoreKeyring keeps the connection string in the system secret service: the macOS keychain, the Windows
+	// credential manager or the Secret Service of the desktop on Linux.
+	StoreKeyring = "keyring"
+	// StoreEncryptedFile keeps the connection string in an age file encrypted with a passphrase, for systems without
+	// a secret service. The file can also be decrypted with `age -d`.
+	StoreEncryptedFile = "age"
+
+	keyringService = "service-bus-tui"
+)
+
+// SavedConnectionString names a connection string kept in a secret store. The names are listed in
+// connection-strings.json, the connection strings themselves never leave the store unencrypted.
+type SavedConnectionString struct {
+	Name  string `json:"name"`
+	Store string `json:"store"` // StoreKeyring or StoreEncryptedFile
+}
+
+type connectionStringsFile struct {
+	ConnectionStrings []SavedConnectionString `json:"connectionStrings"`
+}
+
+// validConnectionStringName keeps names usable as file names.
+var validConnectionStringName = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._ -]*$`)
+
+// ErrWrongPassphrase is returned when an encrypted connection string can't be decrypted with the passphrase.
+var ErrWrongPassphrase = errors.New("wrong passphrase")
+
+// KeyringAvailable reports whether the system secret service can be used. It is not on headless Linux hosts without
+// a Secret Service provider, where connection strings go to encrypted files instead.
+func KeyringAvailable() bool {
+	_, err := keyring.Get(keyringService, "probe")
+	return err == nil || errors.Is(err, keyring.ErrNotFound)
+}
+
+// LoadSavedConnectionStrings returns the names of the saved connection strings, sorted by name.
+func LoadSavedConnectionStrings() ([]SavedConnectionString, error) {
+	path, err := connectionStringsPath()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read saved connection strings: %w", err)
+	}
+
+	var file connectionStringsFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	sortConnectionStrings(file.ConnectionStrings)
+	return file.ConnectionStrings, nil
+}
+
+// SaveConnectionString stores connectionString under name in store, replacing the connection string of the same
+// name. passphrase is only used by StoreEncryptedFile.
+func SaveConnectionString(name, connectionString, store, passphrase string) error {
+	if !validConnectionStringName.MatchString(name) {
+		return fmt.Errorf("name must start with a letter or digit and contain only letters, digits, '.', '_', '-' and spaces")
+	}
+
+	saved, err := LoadSavedConnectionStrings()
+	if err != nil {
+		return err
+	}
+
+	switch store {
+	case StoreKeyring:
+		if err := keyring.Set(keyringService, name, connectionString); err != nil {
+			return fmt.Errorf("failed to save connection string in the keyring: %w", err)
+		}
+	case StoreEncryptedFile:
+		if err := writeEncryptedConnectionString(name, connectionString, passphrase); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unknown secret store %q", store)
+	}
+
+	// A name moving to another store must not stay in the previous one. It is only removed from there once the new
+	// copy is saved, so a failed save doesn't lose the connection string.
+	var previous []SavedConnectionString
+	saved = slices.DeleteFunc(saved, func(s SavedConnectionString) bool {
+		if s.Name == name && s.Store != store {
+			previous = append(previous, s)
+		}
+		return s.Name == name
+	})
+	if err := writeConnectionStrings(append(saved, SavedConnectionString{Name: name, Store: store})); err != nil {
+		return err
+	}
+	for _, s := range previous {
+		if err := deleteSecret(s); err != nil {
+			return fmt.Errorf("connection string %s was saved, but its previous copy was not removed: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// LoadConnectionString returns the connection string saved as s. passphrase is only used by StoreEncryptedFile.
+func LoadConnectionString(s SavedConnectionString, passphrase string) (string, error) {
+	switch s.Store {
+	case StoreKeyring:
+		connectionString, err := keyring.Get(keyringService, s.Name)
+		if err != nil {
+			return "", fmt.Errorf("failed to read connection string %s from the keyring: %w", s.Name, err)
+		}
+		return connectionString, nil
+	case StoreEncryptedFile:
+		return readEncryptedConnectionString(s.Name, passphrase)
+	}
+	return "", fmt.Errorf("unknown secret store %q", s.Store)
+}
+
+// DeleteConnectionString removes the connection string saved as s from its store.
+func DeleteConnectionString(s SavedConnectionString) error {
+	if err := deleteSecret(s); err != nil {
+		return err
+	}
+
+	saved, err := LoadSavedConnectionStrings()
+	if err != nil {
+		return err
+	}
+	return writeConnectionStrings(slices.DeleteFunc(saved, func(other SavedConnectionString) bool {
+		return other.Name == s.Name
+	}))
+}
+
+func deleteSecret(s SavedConnectionString) error {
+	switch s.Store {
+	case StoreKeyring:
+		if err := keyring.Delete(keyringService, s.Name); err != nil && !errors.Is(err, keyring.ErrNotFound) {
+			return fmt.Errorf("failed to delete connection string %s from the keyring: %w", s.Name, err)
+		}
+	case StoreEncryptedFile:
+		path, err := encryptedConnectionStringPath(s.Name)
+		if err != nil {
+			return err
+		}
+		if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("failed to delete connection string %s: %w", s.Name, err)
+		}
+	}
+	return nil
+}
+
+func writeEncryptedConnectionString(name, connectionString, passphrase string) error {
+	if passphrase == "" {
+		return fmt.Errorf("passphrase cannot be empty")
+	}
+	recipient, err := age.NewScryptRecipient(passphrase)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt connection string: %w", err)
+	}
+
+	var buf bytes.Buffer
+	w, err := age.Encrypt(&buf, recipient)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt connection string: %w", err)
+	}
+	if _, err := io.WriteString(w, connectionString); err != nil {
+		return fmt.Errorf("failed to encrypt connection string: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("failed to encrypt connection string: %w", err)
+	}
+
+	path, err := encryptedConnectionStringPath(name)
+	if err != nil {
+		return err
+	}
+	if err := writeFileAtomic(path, buf.Bytes()); err != nil {
+		return fmt.Errorf("failed to save connection string: %w", err)
+	}
+	return nil
+}
+
+func readEncryptedConnectionString(name, passphrase string) (string, error) {
+	path, err := encryptedConnectionStringPath(name)
+	if err != nil {
+		return "", err
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read connection string %s: %w", name, err)
+	}
+	defer f.Close()
+
+	identity, err := age.NewScryptIdentity(passphrase)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt connection string %s: %w", name, err)
+	}
+	r, err := age.Decrypt(f, identity)
+	var noMatch *age.NoIdentityMatchError
+	if errors.As(err, &noMatch) {
+		return "", ErrWrongPassphrase
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt connection string %s: %w", name, err)
+	}
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt connection string %s: %w", name, err)
+	}
+	return string(data), nil
+}
+
+func connectionStringsPath() (string, error) {
+	dir, err := configDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "connection-strings.json"), nil
+}
+
+// encryptedConnectionStringPath returns the age file of the connection string called name, e.g.
+// ~/.config/service-bus-tui/connection-strings/dev.age on Linux.
+func encryptedConnectionStringPath(name string) (string, error) {
+	dir, err := configDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "connection-strings", name+".age"), nil
+}
+
+func writeConnectionStrings(saved []SavedConnectionString) error {
+	path, err := connectionStringsPath()
+	if err != nil {
+		return err
+	}
+
+	sortConnectionStrings(saved)
+	data, err := json.MarshalIndent(connectionStringsFile{ConnectionStrings: saved}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode saved connection strings: %w", err)
+	}
+	if err := writeFileAtomic(path, append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to save connection strings: %w", err)
+	}
+	return nil
+}
+
+func sortConnectionStrings(saved []SavedConnectionString) {
+	slices.SortFunc(saved, func(a, b SavedConnectionString) int {
+		return strings.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name))
+	})
+}
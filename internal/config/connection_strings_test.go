@@ -0,0 +1,83 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/zalando/go-keyring"
+)
+
+// useTempConfigDir points configDir at an empty directory and the keyring at an in-memory one.
+func useTempConfigDir(t *testing.T) {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	t.Setenv("HOME", dir)
+	t.Setenv("AppData", dir)
+	keyring.MockInit()
+}
+
+func TestEncryptedConnectionStringRoundTrip(t *testing.T) {
+	useTempConfigDir(t)
+	const connectionString = "Endpoint=sb://example.servicebus.windows.net/;SharedAccessKeyName=k;SharedAccessKey=s"
+
+	if err := SaveConnectionString("dev", connectionString, StoreEncryptedFile, "correct horse"); err != nil {
+		t.Fatalf("SaveConnectionString: %v", err)
+	}
+
+	saved, err := LoadSavedConnectionStrings()
+	if err != nil {
+		t.Fatalf("LoadSavedConnectionStrings: %v", err)
+	}
+	want := SavedConnectionString{Name: "dev", Store: StoreEncryptedFile}
+	if len(saved) != 1 || saved[0] != want {
+		t.Fatalf("LoadSavedConnectionStrings() = %v, want [%v]", saved, want)
+	}
+
+	got, err := LoadConnectionString(want, "correct horse")
+	if err != nil {
+		t.Fatalf("LoadConnectionString: %v", err)
+	}
+	if got != connectionString {
+		t.Errorf("LoadConnectionString() = %q, want %q", got, connectionString)
+	}
+
+	if _, err := LoadConnectionString(want, "wrong horse"); !errors.Is(err, ErrWrongPassphrase) {
+		t.Errorf("LoadConnectionString with the wrong passphrase returned %v, want ErrWrongPassphrase", err)
+	}
+}
+
+func TestSaveConnectionStringMovesStore(t *testing.T) {
+	useTempConfigDir(t)
+	if err := SaveConnectionString("dev", "first", StoreEncryptedFile, "passphrase"); err != nil {
+		t.Fatalf("SaveConnectionString: %v", err)
+	}
+	path, err := encryptedConnectionStringPath("dev")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keyring.MockInitWithError(errors.New("no secret service"))
+	if err := SaveConnectionString("dev", "second", StoreKeyring, ""); err == nil {
+		t.Fatal("expected an error when the keyring fails")
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("the encrypted file was removed although the keyring failed: %v", err)
+	}
+
+	keyring.MockInit()
+	if err := SaveConnectionString("dev", "second", StoreKeyring, ""); err != nil {
+		t.Fatalf("SaveConnectionString: %v", err)
+	}
+	if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("the encrypted file was kept after moving to the keyring: %v", err)
+	}
+	got, err := LoadConnectionString(SavedConnectionString{Name: "dev", Store: StoreKeyring}, "")
+	if err != nil {
+		t.Fatalf("LoadConnectionString: %v", err)
+	}
+	if got != "second" {
+		t.Errorf("LoadConnectionString() = %q, want %q", got, "second")
+	}
+}
@@ -0,0 +1,168 @@
+// Package config stores the connection profiles and saved connection strings of service-bus-tui in the user config
+// directory.
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+const (
+	AuthAzureCLI                    = "azure-cli"
+	AuthInteractiveBrowser          = "interactive-browser"
+	AuthDeviceCode                  = "device-code"
+	AuthServicePrincipalSecret      = "service-principal-secret"
+	AuthServicePrincipalCertificate = "service-principal-certificate"
+	AuthManagedIdentitySystem       = "managed-identity-system"
+	AuthManagedIdentityUser         = "managed-identity-user"
+	AuthWorkloadIdentity            = "workload-identity"
+	AuthEnvironment                 = "environment"
+)
+
+// Profile records how to connect to a namespace. Secrets are never stored: client secrets and certificate passwords
+// come from the environment, or are asked for when connecting.
+type Profile struct {
+	Name            string `json:"name"`
+	Auth            string `json:"auth"`            // one of the Auth* constants
+	Cloud           string `json:"cloud,omitempty"` // one of azure.CloudNames, the public cloud when empty
+	TenantID        string `json:"tenantId,omitempty"`
+	ClientID        string `json:"clientId,omitempty"` // service principal or user-assigned identity
+	CertificatePath string `json:"certificatePath,omitempty"`
+	Subscription    string `json:"subscription,omitempty"`
+	ResourceGroup   string `json:"resourceGroup,omitempty"`
+	Namespace       string `json:"namespace"`
+}
+
+type profilesFile struct {
+	Profiles []Profile `json:"profiles"`
+}
+
+// ProfilesPath returns the path of the profiles file, e.g. ~/.config/service-bus-tui/profiles.json on Linux.
+func ProfilesPath() (string, error) {
+	dir, err := configDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "profiles.json"), nil
+}
+
+// configDir returns the directory of the files of service-bus-tui in the user config directory.
+func configDir() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to find the user config directory: %w", err)
+	}
+	return filepath.Join(dir, "service-bus-tui"), nil
+}
+
+// LoadProfiles returns the saved profiles, sorted by name, or none when the profiles file does not exist yet.
+func LoadProfiles() ([]Profile, error) {
+	path, err := ProfilesPath()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read profiles: %w", err)
+	}
+
+	var file profilesFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	sortProfiles(file.Profiles)
+	return file.Profiles, nil
+}
+
+// FindProfile returns the saved profile called name.
+func FindProfile(name string) (Profile, error) {
+	profiles, err := LoadProfiles()
+	if err != nil {
+		return Profile{}, err
+	}
+	for _, p := range profiles {
+		if p.Name == name {
+			return p, nil
+		}
+	}
+	return Profile{}, fmt.Errorf("profile %q not found", name)
+}
+
+// SaveProfile adds p to the saved profiles, replacing the profile of the same name.
+func SaveProfile(p Profile) error {
+	if strings.TrimSpace(p.Name) == "" {
+		return fmt.Errorf("profile name cannot be empty")
+	}
+
+	profiles, err := LoadProfiles()
+	if err != nil {
+		return err
+	}
+	profiles = slices.DeleteFunc(profiles, func(other Profile) bool { return other.Name == p.Name })
+	return writeProfiles(append(profiles, p))
+}
+
+// DeleteProfile removes the saved profile called name.
+func DeleteProfile(name string) error {
+	profiles, err := LoadProfiles()
+	if err != nil {
+		return err
+	}
+	return writeProfiles(slices.DeleteFunc(profiles, func(p Profile) bool { return p.Name == name }))
+}
+
+func writeProfiles(profiles []Profile) error {
+	path, err := ProfilesPath()
+	if err != nil {
+		return err
+	}
+
+	sortProfiles(profiles)
+	data, err := json.MarshalIndent(profilesFile{Profiles: profiles}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode profiles: %w", err)
+	}
+	if err := writeFileAtomic(path, append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to save profiles: %w", err)
+	}
+	return nil
+}
+
+// writeFileAtomic writes next to path and renames, so a failed write never loses the existing file. The file and
+// its directory are only readable by the user.
+func writeFileAtomic(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func sortProfiles(profiles []Profile) {
+	slices.SortFunc(profiles, func(a, b Profile) int {
+		return strings.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name))
+	})
+}
@@ -0,0 +1,459 @@
+package sqlfilter
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Expressions evaluate to nil (SQL NULL, or unknown for conditions), bool, int64, float64, string,
+// time.Time or time.Duration. Conditions follow SQL three-valued logic: comparisons involving NULL are unknown,
+// and a filter only matches when it evaluates to true.
+type node interface {
+	eval(msg Message) (any, error)
+}
+
+type literalNode struct{ value any }
+
+func (n literalNode) eval(Message) (any, error) { return n.value, nil }
+
+type propertyNode struct {
+	scope string
+	name  string
+}
+
+func (n propertyNode) lookup(msg Message) (any, bool) {
+	if n.scope == scopeSystem {
+		return msg.SystemProperty(n.name)
+	}
+	return msg.UserProperty(n.name)
+}
+
+func (n propertyNode) eval(msg Message) (any, error) {
+	v, ok := n.lookup(msg)
+	if !ok {
+		return nil, nil
+	}
+	return normalize(v), nil
+}
+
+type existsNode struct{ property node }
+
+func (n existsNode) eval(msg Message) (any, error) {
+	_, ok := n.property.(propertyNode).lookup(msg)
+	return ok, nil
+}
+
+type notNode struct{ operand node }
+
+func (n notNode) eval(msg Message) (any, error) {
+	v, err := evalCondition(n.operand, msg)
+	if err != nil || v == nil {
+		return nil, err
+	}
+	return !*v, nil
+}
+
+type andNode struct{ left, right node }
+
+func (n andNode) eval(msg Message) (any, error) {
+	l, err := evalCondition(n.left, msg)
+	if err != nil {
+		return nil, err
+	}
+	if l != nil && !*l {
+		return false, nil
+	}
+	r, err := evalCondition(n.right, msg)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case r != nil && !*r:
+		return false, nil
+	case l == nil || r == nil:
+		return nil, nil
+	}
+	return true, nil
+}
+
+type orNode struct{ left, right node }
+
+func (n orNode) eval(msg Message) (any, error) {
+	l, err := evalCondition(n.left, msg)
+	if err != nil {
+		return nil, err
+	}
+	if l != nil && *l {
+		return true, nil
+	}
+	r, err := evalCondition(n.right, msg)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case r != nil && *r:
+		return true, nil
+	case l == nil || r == nil:
+		return nil, nil
+	}
+	return false, nil
+}
+
+// evalCondition evaluates a boolean expression, returning nil when it is unknown.
+func evalCondition(n node, msg Message) (*bool, error) {
+	v, err := n.eval(msg)
+	if err != nil || v == nil {
+		return nil, err
+	}
+	b, ok := v.(bool)
+	if !ok {
+		return nil, fmt.Errorf("expected a condition, got %s", describe(v))
+	}
+	return &b, nil
+}
+
+type compareNode struct {
+	op          string
+	left, right node
+}
+
+func (n compareNode) eval(msg Message) (any, error) {
+	l, r, err := evalOperands(n.left, n.right, msg)
+	if err != nil || l == nil || r == nil {
+		return nil, err
+	}
+
+	cmp, ok := compare(l, r)
+	if !ok {
+		return nil, fmt.Errorf("cannot compare %s with %s", describe(l), describe(r))
+	}
+
+	switch n.op {
+	case "=":
+		return cmp == 0, nil
+	case "<>":
+		return cmp != 0, nil
+	}
+
+	if _, isBool := l.(bool); isBool {
+		return nil, fmt.Errorf("operator %s does not apply to booleans", n.op)
+	}
+	switch n.op {
+	case "<":
+		return cmp < 0, nil
+	case "<=":
+		return cmp <= 0, nil
+	case ">":
+		return cmp > 0, nil
+	}
+	return cmp >= 0, nil
+}
+
+type isNullNode struct {
+	operand node
+	negate  bool
+}
+
+func (n isNullNode) eval(msg Message) (any, error) {
+	v, err := n.operand.eval(msg)
+	if err != nil {
+		return nil, err
+	}
+	return (v == nil) != n.negate, nil
+}
+
+type likeNode struct {
+	operand, pattern, escape node
+	negate                   bool
+}
+
+func (n likeNode) eval(msg Message) (any, error) {
+	v, p, err := evalOperands(n.operand, n.pattern, msg)
+	if err != nil || v == nil || p == nil {
+		return nil, err
+	}
+
+	s, ok := v.(string)
+	if !ok {
+		return nil, fmt.Errorf("LIKE expects a string, got %s", describe(v))
+	}
+	pattern, ok := p.(string)
+	if !ok {
+		return nil, fmt.Errorf("LIKE pattern must be a string, got %s", describe(p))
+	}
+
+	var escape rune
+	if n.escape != nil {
+		e, err := n.escape.eval(msg)
+		if err != nil {
+			return nil, err
+		}
+		es, ok := e.(string)
+		if !ok || len([]rune(es)) != 1 {
+			return nil, fmt.Errorf("ESCAPE must be a single character")
+		}
+		escape = []rune(es)[0]
+	}
+
+	re, err := likePattern(pattern, escape)
+	if err != nil {
+		return nil, err
+	}
+	return re.MatchString(s) != n.negate, nil
+}
+
+// likePattern converts a LIKE pattern, where % matches any run of characters and _ a single one, to a regexp.
+func likePattern(pattern string, escape rune) (*regexp.Regexp, error) {
+	var b strings.Builder
+	b.WriteString("(?s)^")
+
+	runes := []rune(pattern)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case escape != 0 && r == escape:
+			if i+1 == len(runes) {
+				return nil, fmt.Errorf("LIKE pattern %q ends with the escape character", pattern)
+			}
+			i++
+			b.WriteString(regexp.QuoteMeta(string(runes[i])))
+		case r == '%':
+			b.WriteString(".*")
+		case r == '_':
+			b.WriteString(".")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+
+	b.WriteString("$")
+	return regexp.Compile(b.String())
+}
+
+type inNode struct {
+	operand node
+	values  []node
+	negate  bool
+}
+
+func (n inNode) eval(msg Message) (any, error) {
+	v, err := n.operand.eval(msg)
+	if err != nil || v == nil {
+		return nil, err
+	}
+
+	for _, valueNode := range n.values {
+		candidate, err := valueNode.eval(msg)
+		if err != nil {
+			return nil, err
+		}
+		if candidate == nil {
+			continue
+		}
+		if cmp, ok := compare(v, candidate); ok && cmp == 0 {
+			return !n.negate, nil
+		}
+	}
+	return n.negate, nil
+}
+
+type arithmeticNode struct {
+	op          string
+	left, right node
+}
+
+func (n arithmeticNode) eval(msg Message) (any, error) {
+	l, r, err := evalOperands(n.left, n.right, msg)
+	if err != nil || l == nil || r == nil {
+		return nil, err
+	}
+
+	if n.op == "+" {
+		if ls, ok := l.(string); ok {
+			if rs, ok := r.(string); ok {
+				return ls + rs, nil
+			}
+		}
+	}
+
+	li, lIsInt := l.(int64)
+	ri, rIsInt := r.(int64)
+	if lIsInt && rIsInt {
+		switch n.op {
+		case "+":
+			return li + ri, nil
+		case "-":
+			return li - ri, nil
+		case "*":
+			return li * ri, nil
+		}
+		if ri == 0 {
+			return nil, fmt.Errorf("division by zero")
+		}
+		if n.op == "/" {
+			return li / ri, nil
+		}
+		return li % ri, nil
+	}
+
+	lf, lok := toFloat(l)
+	rf, rok := toFloat(r)
+	if !lok || !rok {
+		return nil, fmt.Errorf("operator %s does not apply to %s and %s", n.op, describe(l), describe(r))
+	}
+	switch n.op {
+	case "+":
+		return lf + rf, nil
+	case "-":
+		return lf - rf, nil
+	case "*":
+		return lf * rf, nil
+	case "/":
+		if rf == 0 {
+			return nil, fmt.Errorf("division by zero")
+		}
+		return lf / rf, nil
+	}
+	return nil, fmt.Errorf("operator %% expects integers")
+}
+
+type negateNode struct{ operand node }
+
+func (n negateNode) eval(msg Message) (any, error) {
+	v, err := n.operand.eval(msg)
+	if err != nil || v == nil {
+		return nil, err
+	}
+	switch v := v.(type) {
+	case int64:
+		return -v, nil
+	case float64:
+		return -v, nil
+	}
+	return nil, fmt.Errorf("cannot negate %s", describe(v))
+}
+
+func evalOperands(left, right node, msg Message) (any, any, error) {
+	l, err := left.eval(msg)
+	if err != nil {
+		return nil, nil, err
+	}
+	r, err := right.eval(msg)
+	if err != nil {
+		return nil, nil, err
+	}
+	return l, r, nil
+}
+
+// compare orders two non-nil values of compatible types. Integers and floats compare with each other.
+func compare(l, r any) (int, bool) {
+	if lf, ok := toFloat(l); ok {
+		rf, ok := toFloat(r)
+		if !ok {
+			return 0, false
+		}
+		if li, ok := l.(int64); ok {
+			if ri, ok := r.(int64); ok {
+				return cmpOrdered(li, ri), true
+			}
+		}
+		return cmpOrdered(lf, rf), true
+	}
+
+	switch l := l.(type) {
+	case string:
+		if r, ok := r.(string); ok {
+			return strings.Compare(l, r), true
+		}
+	case bool:
+		if r, ok := r.(bool); ok {
+			if l == r {
+				return 0, true
+			}
+			return 1, true
+		}
+	case time.Time:
+		if r, ok := r.(time.Time); ok {
+			return l.Compare(r), true
+		}
+	case time.Duration:
+		if r, ok := r.(time.Duration); ok {
+			return cmpOrdered(l, r), true
+		}
+	}
+	return 0, false
+}
+
+func cmpOrdered[T int64 | float64 | time.Duration](l, r T) int {
+	switch {
+	case l < r:
+		return -1
+	case l > r:
+		return 1
+	}
+	return 0
+}
+
+func toFloat(v any) (float64, bool) {
+	switch v := v.(type) {
+	case int64:
+		return float64(v), true
+	case float64:
+		return v, true
+	}
+	return 0, false
+}
+
+// normalize converts property values to the types expressions work with.
+func normalize(v any) any {
+	switch v := v.(type) {
+	case nil, bool, int64, float64, string, time.Time, time.Duration:
+		return v
+	case int:
+		return int64(v)
+	case int8:
+		return int64(v)
+	case int16:
+		return int64(v)
+	case int32:
+		return int64(v)
+	case uint8:
+		return int64(v)
+	case uint16:
+		return int64(v)
+	case uint32:
+		return int64(v)
+	case uint64:
+		return int64(v)
+	case uint:
+		return int64(v)
+	case float32:
+		return float64(v)
+	case *string:
+		if v == nil {
+			return nil
+		}
+		return *v
+	case fmt.Stringer:
+		return v.String()
+	}
+	return fmt.Sprintf("%v", v)
+}
+
+func describe(v any) string {
+	switch v := v.(type) {
+	case string:
+		return fmt.Sprintf("string %q", v)
+	case int64, float64:
+		return fmt.Sprintf("number %v", v)
+	case bool:
+		return fmt.Sprintf("boolean %v", v)
+	case time.Time:
+		return "timestamp " + v.Format(time.RFC3339)
+	case time.Duration:
+		return "duration " + v.String()
+	}
+	return fmt.Sprintf("%v", v)
+}
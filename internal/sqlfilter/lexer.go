@@ -0,0 +1,146 @@
+package sqlfilter
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdent
+	tokenKeyword
+	tokenString
+	tokenNumber
+	tokenOperator
+	tokenParameter
+)
+
+type token struct {
+	kind tokenKind
+	text string // keywords are upper-cased, quoted identifiers and strings are unquoted, parameters keep their @
+	pos  int
+}
+
+var keywords = map[string]bool{
+	"AND": true, "OR": true, "NOT": true, "LIKE": true, "ESCAPE": true, "IN": true,
+	"IS": true, "NULL": true, "EXISTS": true, "TRUE": true, "FALSE": true,
+}
+
+// twoCharOperators are matched before the single characters in singleCharOperators.
+var twoCharOperators = []string{"<>", "!=", "<=", ">="}
+
+const singleCharOperators = "=<>+-*/%(),."
+
+func tokenize(s string) ([]token, error) {
+	var tokens []token
+	runes := []rune(s)
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+
+		switch {
+		case unicode.IsSpace(r):
+			i++
+
+		case r == '\'':
+			text, next, err := scanQuoted(runes, i, '\'')
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, token{kind: tokenString, text: text, pos: i})
+			i = next
+
+		case r == '[':
+			text, next, err := scanQuoted(runes, i, ']')
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, token{kind: tokenIdent, text: text, pos: i})
+			i = next
+
+		case unicode.IsDigit(r) || (r == '.' && i+1 < len(runes) && unicode.IsDigit(runes[i+1])):
+			start := i
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.') {
+				i++
+			}
+			if i < len(runes) && (runes[i] == 'e' || runes[i] == 'E') {
+				i++
+				if i < len(runes) && (runes[i] == '+' || runes[i] == '-') {
+					i++
+				}
+				for i < len(runes) && unicode.IsDigit(runes[i]) {
+					i++
+				}
+			}
+			tokens = append(tokens, token{kind: tokenNumber, text: string(runes[start:i]), pos: start})
+
+		case r == '@' && i+1 < len(runes) && isIdentStart(runes[i+1]):
+			start := i
+			i++
+			for i < len(runes) && isIdentPart(runes[i]) {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenParameter, text: string(runes[start:i]), pos: start})
+
+		case isIdentStart(r):
+			start := i
+			for i < len(runes) && isIdentPart(runes[i]) {
+				i++
+			}
+			text := string(runes[start:i])
+			if upper := strings.ToUpper(text); keywords[upper] {
+				tokens = append(tokens, token{kind: tokenKeyword, text: upper, pos: start})
+			} else {
+				tokens = append(tokens, token{kind: tokenIdent, text: text, pos: start})
+			}
+
+		default:
+			op := ""
+			for _, candidate := range twoCharOperators {
+				if i+1 < len(runes) && string(runes[i:i+2]) == candidate {
+					op = candidate
+					break
+				}
+			}
+			if op == "" && strings.ContainsRune(singleCharOperators, r) {
+				op = string(r)
+			}
+			if op == "" {
+				return nil, fmt.Errorf("unexpected character %q at position %d", r, i+1)
+			}
+			tokens = append(tokens, token{kind: tokenOperator, text: op, pos: i})
+			i += len([]rune(op))
+		}
+	}
+
+	return append(tokens, token{kind: tokenEOF, pos: len(runes)}), nil
+}
+
+func isIdentStart(r rune) bool {
+	return unicode.IsLetter(r) || r == '_' || r == '$'
+}
+
+func isIdentPart(r rune) bool {
+	return isIdentStart(r) || unicode.IsDigit(r)
+}
+
+// scanQuoted reads a string or identifier starting at runes[start]. A doubled closing quote stands for itself.
+func scanQuoted(runes []rune, start int, closing rune) (string, int, error) {
+	var b strings.Builder
+	for i := start + 1; i < len(runes); i++ {
+		if runes[i] != closing {
+			b.WriteRune(runes[i])
+			continue
+		}
+		if i+1 < len(runes) && runes[i+1] == closing {
+			b.WriteRune(closing)
+			i++
+			continue
+		}
+		return b.String(), i + 1, nil
+	}
+	return "", 0, fmt.Errorf("unterminated %c at position %d", runes[start], start+1)
+}
@@ -0,0 +1,344 @@
+package sqlfilter
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Property scopes. Properties without a scope are user properties.
+const (
+	scopeSystem = "sys"
+	scopeUser   = "user"
+)
+
+type parser struct {
+	tokens     []token
+	pos        int
+	parameters map[string]any
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+// accept consumes the next token when it is the given keyword or operator.
+func (p *parser) accept(kind tokenKind, text string) bool {
+	t := p.peek()
+	if t.kind == kind && t.text == text {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) expect(kind tokenKind, text string) error {
+	if !p.accept(kind, text) {
+		return p.errorf("expected %s", text)
+	}
+	return nil
+}
+
+func (p *parser) errorf(format string, args ...any) error {
+	t := p.peek()
+	found := "end of expression"
+	if t.kind != tokenEOF {
+		found = fmt.Sprintf("%q", t.text)
+	}
+	return fmt.Errorf("%s at position %d, found %s", fmt.Sprintf(format, args...), t.pos+1, found)
+}
+
+func (p *parser) parseOr() (node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept(tokenKeyword, "OR") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = orNode{left, right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (node, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept(tokenKeyword, "AND") {
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = andNode{left, right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseNot() (node, error) {
+	if p.accept(tokenKeyword, "NOT") {
+		operand, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return notNode{operand}, nil
+	}
+	return p.parsePredicate()
+}
+
+func (p *parser) parsePredicate() (node, error) {
+	if p.accept(tokenKeyword, "EXISTS") {
+		if err := p.expect(tokenOperator, "("); err != nil {
+			return nil, err
+		}
+		prop, err := p.parseProperty()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(tokenOperator, ")"); err != nil {
+			return nil, err
+		}
+		return existsNode{prop}, nil
+	}
+
+	left, err := p.parseAdditive()
+	if err != nil {
+		return nil, err
+	}
+
+	if t := p.peek(); t.kind == tokenOperator {
+		switch t.text {
+		case "=", "<>", "!=", "<", "<=", ">", ">=":
+			p.next()
+			right, err := p.parseAdditive()
+			if err != nil {
+				return nil, err
+			}
+			op := t.text
+			if op == "!=" {
+				op = "<>"
+			}
+			return compareNode{op: op, left: left, right: right}, nil
+		}
+	}
+
+	if p.accept(tokenKeyword, "IS") {
+		negate := p.accept(tokenKeyword, "NOT")
+		if err := p.expect(tokenKeyword, "NULL"); err != nil {
+			return nil, err
+		}
+		return isNullNode{operand: left, negate: negate}, nil
+	}
+
+	negate := p.accept(tokenKeyword, "NOT")
+	switch {
+	case p.accept(tokenKeyword, "LIKE"):
+		return p.parseLike(left, negate)
+	case p.accept(tokenKeyword, "IN"):
+		return p.parseIn(left, negate)
+	case negate:
+		return nil, p.errorf("expected LIKE or IN after NOT")
+	}
+
+	return left, nil
+}
+
+func (p *parser) parseLike(operand node, negate bool) (node, error) {
+	pattern, err := p.parseAdditive()
+	if err != nil {
+		return nil, err
+	}
+
+	var escape node
+	if p.accept(tokenKeyword, "ESCAPE") {
+		if escape, err = p.parseAdditive(); err != nil {
+			return nil, err
+		}
+	}
+
+	return likeNode{operand: operand, pattern: pattern, escape: escape, negate: negate}, nil
+}
+
+func (p *parser) parseIn(operand node, negate bool) (node, error) {
+	if err := p.expect(tokenOperator, "("); err != nil {
+		return nil, err
+	}
+
+	var values []node
+	for {
+		value, err := p.parseAdditive()
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+
+		if p.accept(tokenOperator, ")") {
+			break
+		}
+		if !p.accept(tokenOperator, ",") {
+			return nil, p.errorf("expected , or )")
+		}
+	}
+
+	return inNode{operand: operand, values: values, negate: negate}, nil
+}
+
+func (p *parser) parseAdditive() (node, error) {
+	left, err := p.parseMultiplicative()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		t := p.peek()
+		if t.kind != tokenOperator || (t.text != "+" && t.text != "-") {
+			return left, nil
+		}
+		p.next()
+		right, err := p.parseMultiplicative()
+		if err != nil {
+			return nil, err
+		}
+		left = arithmeticNode{op: t.text, left: left, right: right}
+	}
+}
+
+func (p *parser) parseMultiplicative() (node, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		t := p.peek()
+		if t.kind != tokenOperator || (t.text != "*" && t.text != "/" && t.text != "%") {
+			return left, nil
+		}
+		p.next()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = arithmeticNode{op: t.text, left: left, right: right}
+	}
+}
+
+func (p *parser) parseUnary() (node, error) {
+	if p.accept(tokenOperator, "-") {
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return negateNode{operand}, nil
+	}
+	p.accept(tokenOperator, "+")
+	return p.parsePrimary()
+}
+
+func (p *parser) parsePrimary() (node, error) {
+	t := p.peek()
+
+	switch t.kind {
+	case tokenString:
+		p.next()
+		return literalNode{t.text}, nil
+
+	case tokenNumber:
+		p.next()
+		if i, err := strconv.ParseInt(t.text, 10, 64); err == nil {
+			return literalNode{i}, nil
+		}
+		f, err := strconv.ParseFloat(t.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q at position %d", t.text, t.pos+1)
+		}
+		return literalNode{f}, nil
+
+	case tokenKeyword:
+		switch t.text {
+		case "TRUE":
+			p.next()
+			return literalNode{true}, nil
+		case "FALSE":
+			p.next()
+			return literalNode{false}, nil
+		case "NULL":
+			p.next()
+			return literalNode{nil}, nil
+		}
+
+	case tokenIdent:
+		return p.parseProperty()
+
+	case tokenParameter:
+		p.next()
+		v, ok := p.parameter(t.text)
+		if !ok {
+			return nil, fmt.Errorf("undefined parameter %s at position %d", t.text, t.pos+1)
+		}
+		return literalNode{normalize(v)}, nil
+
+	case tokenOperator:
+		if t.text == "(" {
+			p.next()
+			inner, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect(tokenOperator, ")"); err != nil {
+				return nil, err
+			}
+			return inner, nil
+		}
+	}
+
+	return nil, p.errorf("expected a value")
+}
+
+// parameter looks up the value of @name. Rules usually key their parameters with the @, but may leave it out;
+// names are case-insensitive.
+func (p *parser) parameter(name string) (any, bool) {
+	for _, key := range []string{name, strings.TrimPrefix(name, "@")} {
+		if v, ok := p.parameters[key]; ok {
+			return v, true
+		}
+	}
+	for key, v := range p.parameters {
+		if strings.EqualFold(strings.TrimPrefix(key, "@"), strings.TrimPrefix(name, "@")) {
+			return v, true
+		}
+	}
+	return nil, false
+}
+
+// parseProperty reads "name", "user.name" or "sys.name". Either part may be quoted as [name].
+func (p *parser) parseProperty() (node, error) {
+	if p.peek().kind != tokenIdent {
+		return nil, p.errorf("expected a property name")
+	}
+	t := p.next()
+
+	if !p.accept(tokenOperator, ".") {
+		return propertyNode{scope: scopeUser, name: t.text}, nil
+	}
+
+	scope := strings.ToLower(t.text)
+	if scope != scopeSystem && scope != scopeUser {
+		return nil, fmt.Errorf("unknown property scope %q at position %d, expected sys or user", t.text, t.pos+1)
+	}
+
+	if p.peek().kind != tokenIdent {
+		return nil, p.errorf("expected a property name")
+	}
+	name := p.next()
+	return propertyNode{scope: scope, name: name.text}, nil
+}
@@ -0,0 +1,69 @@
+// Package sqlfilter evaluates Service Bus SQL filter expressions locally, so rules can be tested against peeked
+// messages without a connection to the namespace.
+//
+// It supports comparisons (=, <>, !=, <, <=, >, >=), arithmetic, AND, OR, NOT, LIKE with ESCAPE, IN, IS NULL,
+// EXISTS, and properties scoped with sys. (system properties, e.g. sys.Label) or user. (application properties,
+// the default when no scope is given). Identifiers may be quoted as [name], and @name references a parameter of
+// the filter, see ParseWithParameters.
+package sqlfilter
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Message exposes the properties a filter can reference. ok is false when the message doesn't have the property.
+type Message interface {
+	SystemProperty(name string) (value any, ok bool)
+	UserProperty(name string) (value any, ok bool)
+}
+
+// Filter is a parsed SQL filter expression.
+type Filter struct {
+	expression string
+	root       node
+}
+
+// Parse parses a SQL filter expression such as "sys.Label = 'order' AND quantity > 10".
+func Parse(expression string) (*Filter, error) {
+	return ParseWithParameters(expression, nil)
+}
+
+// ParseWithParameters parses a SQL filter expression that references parameters, such as "color = @color", and
+// resolves them from parameters, keyed as "@color" or "color".
+func ParseWithParameters(expression string, parameters map[string]any) (*Filter, error) {
+	if strings.TrimSpace(expression) == "" {
+		return nil, fmt.Errorf("filter expression is empty")
+	}
+
+	tokens, err := tokenize(expression)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens, parameters: parameters}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.peek().kind != tokenEOF {
+		return nil, p.errorf("unexpected token")
+	}
+
+	return &Filter{expression: expression, root: root}, nil
+}
+
+// Match reports whether msg satisfies the filter. Expressions that are unknown, such as comparisons with a
+// missing property, don't match. An error is returned when the expression can't be evaluated for msg, e.g. when
+// it compares a string with a number.
+func (f *Filter) Match(msg Message) (bool, error) {
+	v, err := evalCondition(f.root, msg)
+	if err != nil {
+		return false, err
+	}
+	return v != nil && *v, nil
+}
+
+func (f *Filter) String() string {
+	return f.expression
+}
@@ -0,0 +1,287 @@
+package sqlfilter
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+type testMessage struct {
+	system map[string]any
+	user   map[string]any
+}
+
+func (m testMessage) SystemProperty(name string) (any, bool) {
+	for k, v := range m.system {
+		if strings.EqualFold(k, name) {
+			return v, true
+		}
+	}
+	return nil, false
+}
+
+func (m testMessage) UserProperty(name string) (any, bool) {
+	v, ok := m.user[name]
+	return v, ok
+}
+
+var enqueued = time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+var message = testMessage{
+	system: map[string]any{
+		"MessageId":       "msg-1",
+		"Label":           "order.created",
+		"ContentType":     "application/json",
+		"SequenceNumber":  int64(42),
+		"EnqueuedTimeUtc": enqueued,
+	},
+	user: map[string]any{
+		"color":      "red",
+		"quantity":   int32(12),
+		"price":      9.5,
+		"priority":   true,
+		"region":     "eu-west",
+		"empty":      nil,
+		"with space": "yes",
+		"path":       "a%b",
+	},
+}
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		expression string
+		want       bool
+	}{
+		// comparisons
+		{"color = 'red'", true},
+		{"color = 'blue'", false},
+		{"color <> 'blue'", true},
+		{"color != 'red'", false},
+		{"quantity > 10", true},
+		{"quantity >= 12", true},
+		{"quantity < 12", false},
+		{"quantity <= 12.0", true},
+		{"price > 9", true},
+		{"price = 9.5", true},
+		{"priority = TRUE", true},
+		{"priority = false", false},
+		{"'abc' < 'abd'", true},
+		{"1 = 1", true},
+
+		// scopes and identifiers
+		{"user.color = 'red'", true},
+		{"USER.color = 'red'", true},
+		{"sys.Label = 'order.created'", true},
+		{"sys.label = 'order.created'", true},
+		{"sys.SequenceNumber = 42", true},
+		{"sys.MessageId = 'msg-1'", true},
+		{"[with space] = 'yes'", true},
+		{"user.[with space] = 'yes'", true},
+		{"Color = 'red'", false},
+
+		// arithmetic
+		{"quantity * 2 = 24", true},
+		{"quantity / 5 = 2", true},
+		{"quantity % 5 = 2", true},
+		{"price * 2 = 19", true},
+		{"quantity + price = 21.5", true},
+		{"-quantity < 0", true},
+		{"2 + 3 * 4 = 14", true},
+		{"(2 + 3) * 4 = 20", true},
+		{"color + '!' = 'red!'", true},
+
+		// logic
+		{"color = 'red' AND quantity > 10", true},
+		{"color = 'red' AND quantity > 20", false},
+		{"color = 'blue' OR quantity > 10", true},
+		{"NOT color = 'blue'", true},
+		{"NOT (color = 'red' OR quantity > 10)", false},
+		{"color = 'blue' OR color = 'green' AND quantity > 10", false},
+		{"(color = 'blue' OR color = 'red') AND quantity > 10", true},
+
+		// LIKE
+		{"sys.Label LIKE 'order.%'", true},
+		{"sys.Label LIKE 'order._reated'", true},
+		{"sys.Label LIKE 'order'", false},
+		{"sys.Label NOT LIKE 'invoice%'", true},
+		{"region LIKE 'EU%'", false},
+		{"path LIKE 'a!%b' ESCAPE '!'", true},
+		{"color LIKE 'a!%b' ESCAPE '!'", false},
+		{"sys.Label LIKE 'order.(created)'", false},
+
+		// IN
+		{"color IN ('red', 'blue')", true},
+		{"color IN ('green', 'blue')", false},
+		{"color NOT IN ('green', 'blue')", true},
+		{"quantity IN (10, 11, 12)", true},
+		{"quantity IN (12.0)", true},
+
+		// NULL and EXISTS
+		{"missing IS NULL", true},
+		{"empty IS NULL", true},
+		{"color IS NULL", false},
+		{"color IS NOT NULL", true},
+		{"EXISTS(color)", true},
+		{"EXISTS(missing)", false},
+		{"EXISTS(empty)", true},
+		{"EXISTS(sys.Label)", true},
+		{"EXISTS(sys.SessionId)", false},
+		{"NOT EXISTS(missing)", true},
+
+		// three-valued logic with missing properties
+		{"missing = 'x'", false},
+		{"missing <> 'x'", false},
+		{"NOT missing = 'x'", false},
+		{"missing = 'x' OR color = 'red'", true},
+		{"missing = 'x' AND color = 'red'", false},
+		{"missing IN ('x')", false},
+		{"missing LIKE '%'", false},
+		{"color = NULL", false},
+
+		// timestamps
+		{"sys.EnqueuedTimeUtc = sys.EnqueuedTimeUtc", true},
+
+		// keywords are case-insensitive
+		{"color = 'red' and not quantity > 20", true},
+		{"color in ('red') or false", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expression, func(t *testing.T) {
+			f, err := Parse(tt.expression)
+			if err != nil {
+				t.Fatalf("Parse(%q) failed: %v", tt.expression, err)
+			}
+			got, err := f.Match(message)
+			if err != nil {
+				t.Fatalf("Match(%q) failed: %v", tt.expression, err)
+			}
+			if got != tt.want {
+				t.Errorf("Match(%q) = %v, want %v", tt.expression, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		expression string
+		wantErr    string
+	}{
+		{"", "empty"},
+		{"   ", "empty"},
+		{"color =", "expected a value"},
+		{"color = 'red", "unterminated"},
+		{"[color = 'red'", "unterminated"},
+		{"color = 'red' AND", "expected a value"},
+		{"(color = 'red'", "expected )"},
+		{"color = 'red')", "unexpected token"},
+		{"color IN 'red'", "expected ("},
+		{"color IN ('red'", "expected , or )"},
+		{"color IS 'red'", "expected NULL"},
+		{"color NOT 'red'", "expected LIKE or IN"},
+		{"EXISTS color", "expected ("},
+		{"EXISTS(1)", "expected a property name"},
+		{"foo.color = 'red'", "unknown property scope"},
+		{"sys. = 1", "expected a property name"},
+		{"color = 'red' # 1", "unexpected character"},
+		{"quantity = 1.2.3", "invalid number"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expression, func(t *testing.T) {
+			_, err := Parse(tt.expression)
+			if err == nil {
+				t.Fatalf("Parse(%q) succeeded, want error containing %q", tt.expression, tt.wantErr)
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Parse(%q) error = %q, want it to contain %q", tt.expression, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestMatchWithParameters(t *testing.T) {
+	parameters := map[string]any{
+		"@color":   "red",
+		"min":      int32(10),
+		"@Regions": "eu-west",
+	}
+
+	tests := []struct {
+		expression string
+		want       bool
+	}{
+		{"color = @color", true},
+		{"color <> @color", false},
+		{"quantity > @min", true},
+		{"quantity > @min + 5", false},
+		{"region IN (@regions, 'us-east')", true},
+		{"sys.Label = 'order.created' AND color = @COLOR", true},
+		{"color = '@color'", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expression, func(t *testing.T) {
+			f, err := ParseWithParameters(tt.expression, parameters)
+			if err != nil {
+				t.Fatalf("ParseWithParameters(%q) failed: %v", tt.expression, err)
+			}
+			got, err := f.Match(message)
+			if err != nil {
+				t.Fatalf("Match(%q) failed: %v", tt.expression, err)
+			}
+			if got != tt.want {
+				t.Errorf("Match(%q) = %v, want %v", tt.expression, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseUndefinedParameter(t *testing.T) {
+	for _, parameters := range []map[string]any{nil, {"@other": 1}} {
+		_, err := ParseWithParameters("color = @color", parameters)
+		if err == nil || !strings.Contains(err.Error(), "undefined parameter @color") {
+			t.Errorf("ParseWithParameters with %v: error = %v, want undefined parameter @color", parameters, err)
+		}
+	}
+
+	if _, err := Parse("color = @ 1"); err == nil || !strings.Contains(err.Error(), "unexpected character") {
+		t.Errorf("Parse of a lone @: error = %v, want unexpected character", err)
+	}
+}
+
+func TestMatchErrors(t *testing.T) {
+	tests := []struct {
+		expression string
+		wantErr    string
+	}{
+		{"color = 1", "cannot compare"},
+		{"quantity LIKE '1%'", "LIKE expects a string"},
+		{"color LIKE 1", "pattern must be a string"},
+		{"color LIKE 'a' ESCAPE 'ab'", "single character"},
+		{"color LIKE 'a!' ESCAPE '!'", "ends with the escape character"},
+		{"quantity / 0 = 1", "division by zero"},
+		{"price % 2 = 1", "expects integers"},
+		{"color - 1 = 1", "does not apply"},
+		{"priority > FALSE", "does not apply to booleans"},
+		{"color", "expected a condition"},
+		{"-color = 1", "cannot negate"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expression, func(t *testing.T) {
+			f, err := Parse(tt.expression)
+			if err != nil {
+				t.Fatalf("Parse(%q) failed: %v", tt.expression, err)
+			}
+			_, err = f.Match(message)
+			if err == nil {
+				t.Fatalf("Match(%q) succeeded, want error containing %q", tt.expression, tt.wantErr)
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Match(%q) error = %q, want it to contain %q", tt.expression, err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -21,11 +21,12 @@ var ansiRegex = regexp.MustCompile(`\x1b\[[0-9;]*m`)
 type Model struct {
 	KeyMap KeyMap
 
-	cols   []Column
-	rows   []Row
-	cursor int
-	focus  bool
-	styles Styles
+	cols        []Column
+	rows        []Row
+	cursor      int
+	focus       bool
+	styles      Styles
+	highlighted map[int]bool
 
 	viewport viewport.Model
 	start    int
@@ -96,17 +97,19 @@ func DefaultKeyMap() KeyMap {
 // Styles contains style definitions for this list component. By default, these
 // values are generated by DefaultStyles.
 type Styles struct {
-	Header   lipgloss.Style
-	Cell     lipgloss.Style
-	Selected lipgloss.Style
+	Header      lipgloss.Style
+	Cell        lipgloss.Style
+	Selected    lipgloss.Style
+	Highlighted lipgloss.Style
 }
 
 // DefaultStyles returns a set of default style definitions for this table.
 func DefaultStyles() Styles {
 	return Styles{
-		Selected: lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("212")),
-		Header:   lipgloss.NewStyle().Bold(true).Padding(0, 1),
-		Cell:     lipgloss.NewStyle().Padding(0, 1),
+		Selected:    lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("212")),
+		Header:      lipgloss.NewStyle().Bold(true).Padding(0, 1),
+		Cell:        lipgloss.NewStyle().Padding(0, 1),
+		Highlighted: lipgloss.NewStyle().Foreground(lipgloss.Color("220")),
 	}
 }
 
@@ -289,6 +292,21 @@ func (m *Model) SetRows(r []Row) {
 	m.UpdateViewport()
 }
 
+// SetHighlightedRows marks the rows at the given indices as highlighted. Passing
+// no indices clears all highlights.
+func (m *Model) SetHighlightedRows(indices ...int) {
+	m.highlighted = make(map[int]bool, len(indices))
+	for _, i := range indices {
+		m.highlighted[i] = true
+	}
+	m.UpdateViewport()
+}
+
+// IsHighlighted reports whether the row at the given index is highlighted.
+func (m Model) IsHighlighted(rowID int) bool {
+	return m.highlighted[rowID]
+}
+
 // SetColumns sets a new columns state.
 func (m *Model) SetColumns(c []Column) {
 	m.cols = c
@@ -399,13 +417,14 @@ func (m Model) headersView() string {
 
 func (m *Model) renderRow(rowID int) string {
 	isSelected := rowID == m.cursor
+	isHighlighted := m.highlighted[rowID]
 	var s = make([]string, 0, len(m.cols))
 	for i, value := range m.rows[rowID] {
 		style := lipgloss.NewStyle().Width(m.cols[i].Width).MaxWidth(m.cols[i].Width).Inline(true)
 		// Use ANSI-aware truncation for cell values (may contain escape codes)
 		truncated := truncate.StringWithTail(value, uint(m.cols[i].Width), "…")
-		// Strip ANSI codes for selected row to allow selection background to show
-		if isSelected {
+		// Strip ANSI codes for selected and highlighted rows to allow their styles to show
+		if isSelected || isHighlighted {
 			truncated = ansiRegex.ReplaceAllString(truncated, "")
 		}
 		renderedCell := m.styles.Cell.Render(style.Render(truncated))
@@ -418,6 +437,10 @@ func (m *Model) renderRow(rowID int) string {
 		return m.styles.Selected.Render(row)
 	}
 
+	if isHighlighted {
+		return m.styles.Highlighted.Render(row)
+	}
+
 	return row
 }
 
@@ -1,20 +1,45 @@
 package main
 
 import (
+	"flag"
 	"log"
+	"os"
+	"strings"
 
 	"github.com/MonsieurTib/service-bus-tui/internal/app"
+	"github.com/MonsieurTib/service-bus-tui/internal/azure"
+	"github.com/MonsieurTib/service-bus-tui/internal/config"
 	tea "github.com/charmbracelet/bubbletea"
 )
 
 func main() {
+	cloudName := flag.String("cloud", os.Getenv("AZURE_CLOUD"),
+		"Azure cloud: "+strings.Join(azure.CloudNames, ", ")+" (default public, or $AZURE_CLOUD)")
+	profileName := flag.String("profile", "", "connect straight to the namespace of the saved profile `name`")
+	flag.Parse()
+
+	cloud, err := azure.CloudByName(*cloudName)
+	if err != nil {
+		log.Fatal(err)
+	}
+	azure.SetCloud(cloud)
+
+	var profile *config.Profile
+	if *profileName != "" {
+		p, err := config.FindProfile(*profileName)
+		if err != nil {
+			log.Fatal(err)
+		}
+		profile = &p
+	}
+
 	f, err := tea.LogToFile("debug.log", "debug")
 	if err != nil {
 		log.Fatalf("failed to create debug log: %v", err)
 	}
 	defer f.Close()
 
-	p := tea.NewProgram(app.NewRootModel(), tea.WithAltScreen())
+	p := tea.NewProgram(app.NewRootModel(profile), tea.WithAltScreen())
 	if _, err := p.Run(); err != nil {
 		log.Fatalf("error running program: %v", err)
 	}